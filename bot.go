@@ -30,6 +30,7 @@ type Bot struct {
 type BotExtraInfo struct {
 	MedianPendingDuration time.Duration `json:"medianPendingDuration"`
 	MedianDuration        time.Duration `json:"medianDuration"`
+	DurationExtraInfo
 }
 
 // GetFullRepoPath returns the full path of the bot repository with source, owner and name
@@ -32,6 +32,8 @@ type Build struct {
 	ManifestObject       *manifest.EstafetteManifest `json:"-"`
 	Groups               []*Group                    `json:"groups,omitempty"`
 	Organizations        []*Organization             `json:"organizations,omitempty"`
+	MatrixKey            string                      `json:"matrixKey,omitempty"`
+	Matrix               map[string]string           `json:"matrix,omitempty"`
 }
 
 // GetFullRepoPath returns the full path of the build repository with source, owner and name
@@ -30,6 +30,8 @@ type BuildLogStep struct {
 	AutoInjected bool                     `json:"autoInjected,omitempty"`
 	NestedSteps  []*BuildLogStep          `json:"nestedSteps,omitempty"`
 	Services     []*BuildLogStep          `json:"services,omitempty"`
+	CacheKey     string                   `json:"cacheKey,omitempty"`
+	CacheHit     bool                     `json:"cacheHit,omitempty"`
 }
 
 // BuildLogStepDockerImage represents info about the docker image used for a step
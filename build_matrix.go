@@ -0,0 +1,290 @@
+package contracts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+const (
+	// BuildMatrixAxisTrack sets the builder track of each expanded config
+	BuildMatrixAxisTrack = "track"
+	// BuildMatrixAxisOperatingSystem sets the builder operating system of each expanded config
+	BuildMatrixAxisOperatingSystem = "os"
+
+	buildMatrixEnvVarPrefix = "ESTAFETTE_MATRIX_"
+	buildMatrixMaxJobName   = 63
+)
+
+var buildMatrixEnvVarInvalidCharactersRegex = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// BuildMatrix runs the same build for every combination of axis values
+type BuildMatrix struct {
+	Axes    []*BuildMatrixAxis  `yaml:"axes" json:"axes"`
+	Include []map[string]string `yaml:"include,omitempty" json:"include,omitempty"`
+	Exclude []map[string]string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
+// BuildMatrixAxis is a named dimension of a build matrix with its values
+type BuildMatrixAxis struct {
+	Name   string   `yaml:"name" json:"name"`
+	Values []string `yaml:"values" json:"values"`
+}
+
+// Validate checks whether the axes and include and exclude rules are valid
+func (m *BuildMatrix) Validate() error {
+	if len(m.Axes) == 0 {
+		return fmt.Errorf("Build matrix needs at least one axis")
+	}
+
+	axes := map[string]bool{}
+	for _, a := range m.Axes {
+		if a == nil || a.Name == "" {
+			return fmt.Errorf("Build matrix axis needs a name")
+		}
+		if axes[a.Name] {
+			return fmt.Errorf("Build matrix axis %v is defined more than once", a.Name)
+		}
+		axes[a.Name] = true
+
+		if len(a.Values) == 0 {
+			return fmt.Errorf("Build matrix axis %v needs at least one value", a.Name)
+		}
+		values := map[string]bool{}
+		for _, v := range a.Values {
+			if values[v] {
+				return fmt.Errorf("Build matrix axis %v has value %v more than once", a.Name, v)
+			}
+			values[v] = true
+		}
+	}
+
+	for _, e := range m.Exclude {
+		for k := range e {
+			if !axes[k] {
+				return fmt.Errorf("Build matrix exclude rule uses unknown axis %v", k)
+			}
+		}
+	}
+	for _, i := range m.Include {
+		for k := range i {
+			if !axes[k] {
+				return fmt.Errorf("Build matrix include rule uses unknown axis %v", k)
+			}
+		}
+		if len(i) != len(axes) {
+			return fmt.Errorf("Build matrix include rule %v needs a value for every axis", GetBuildMatrixKey(i))
+		}
+	}
+
+	return nil
+}
+
+// GetCombinations returns the axis values of every cell in the matrix
+func (m *BuildMatrix) GetCombinations() []map[string]string {
+	combinations := []map[string]string{{}}
+	for _, a := range m.Axes {
+		if a == nil {
+			continue
+		}
+		expanded := []map[string]string{}
+		for _, c := range combinations {
+			for _, v := range a.Values {
+				combination := map[string]string{a.Name: v}
+				for k, cv := range c {
+					combination[k] = cv
+				}
+				expanded = append(expanded, combination)
+			}
+		}
+		combinations = expanded
+	}
+
+	filteredCombinations := []map[string]string{}
+	keys := map[string]bool{}
+	for i, c := range append(combinations, m.Include...) {
+		key := GetBuildMatrixKey(c)
+		if key == "" || keys[key] {
+			continue
+		}
+		// exclude rules don't apply to explicitly included combinations
+		if i < len(combinations) && m.isExcluded(c) {
+			continue
+		}
+		keys[key] = true
+
+		combination := map[string]string{}
+		for k, v := range c {
+			combination[k] = v
+		}
+		filteredCombinations = append(filteredCombinations, combination)
+	}
+
+	return filteredCombinations
+}
+
+func (m *BuildMatrix) isExcluded(combination map[string]string) bool {
+	for _, e := range m.Exclude {
+		if len(e) == 0 {
+			continue
+		}
+		isMatch := true
+		for k, v := range e {
+			if combination[k] != v {
+				isMatch = false
+				break
+			}
+		}
+		if isMatch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ExpandBuildMatrix returns a config per matrix combination with the axis values injected as environment variables
+func ExpandBuildMatrix(config BuilderConfig, matrix BuildMatrix) ([]*BuilderConfig, error) {
+	if err := matrix.Validate(); err != nil {
+		return nil, err
+	}
+
+	configs := []*BuilderConfig{}
+	for _, c := range matrix.GetCombinations() {
+		expandedConfig, err := expandBuilderConfig(config, c)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, expandedConfig)
+	}
+
+	return configs, nil
+}
+
+func expandBuilderConfig(config BuilderConfig, combination map[string]string) (*BuilderConfig, error) {
+	key := GetBuildMatrixKey(combination)
+
+	// copy everything that gets changed, so the configs don't share state
+	expandedConfig := config
+
+	var mft manifest.EstafetteManifest
+	if config.Manifest != nil {
+		mft = config.Manifest.DeepCopy()
+	}
+	if mft.GlobalEnvVars == nil {
+		mft.GlobalEnvVars = map[string]string{}
+	}
+	for k, v := range combination {
+		mft.GlobalEnvVars[GetBuildMatrixEnvVarName(k)] = v
+	}
+
+	if os, ok := combination[BuildMatrixAxisOperatingSystem]; ok {
+		mft.Builder.OperatingSystem = manifest.OperatingSystem(os)
+	}
+	if track, ok := combination[BuildMatrixAxisTrack]; ok {
+		mft.Builder.Track = track
+		expandedConfig.Track = &track
+	}
+	if err := validateBuildMatrixBuilder(config.ManifestPreferences, mft.Builder, key); err != nil {
+		return nil, err
+	}
+	expandedConfig.Manifest = &mft
+
+	jobName := GetBuildMatrixJobName(config.JobName, key)
+	expandedConfig.JobName = &jobName
+
+	if config.Build != nil {
+		build := *config.Build
+		build.MatrixKey = key
+		build.Matrix = combination
+		expandedConfig.Build = &build
+	}
+
+	return &expandedConfig, nil
+}
+
+func validateBuildMatrixBuilder(preferences *manifest.EstafetteManifestPreferences, builder manifest.EstafetteBuilder, key string) error {
+	if preferences == nil {
+		return nil
+	}
+	if builder.OperatingSystem != manifest.OperatingSystemUnknown && len(preferences.BuilderOperatingSystems) > 0 && !manifest.OperatingSystemArrayContains(preferences.BuilderOperatingSystems, builder.OperatingSystem) {
+		return fmt.Errorf("Build matrix combination %v uses operating system %v that isn't allowed by the manifest preferences", key, builder.OperatingSystem)
+	}
+	if builder.Track != "" {
+		if tracks, ok := preferences.BuilderTracksPerOperatingSystem[builder.OperatingSystem]; ok && !containsString(tracks, builder.Track) {
+			return fmt.Errorf("Build matrix combination %v uses builder track %v that isn't allowed for operating system %v", key, builder.Track, builder.OperatingSystem)
+		}
+	}
+
+	return nil
+}
+
+// GetBuildMatrixKey returns a key for a matrix combination like go=1.17,os=linux, with the axes sorted by name
+func GetBuildMatrixKey(combination map[string]string) string {
+	keys := make([]string, 0, len(combination))
+	for k := range combination {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", k, combination[k]))
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+// GetBuildMatrixEnvVarName returns the environment variable an axis value is injected as, like ESTAFETTE_MATRIX_GO_VERSION for axis go-version
+func GetBuildMatrixEnvVarName(axis string) string {
+	name := buildMatrixEnvVarInvalidCharactersRegex.ReplaceAllString(strings.ToUpper(axis), "_")
+
+	return buildMatrixEnvVarPrefix + strings.Trim(name, "_")
+}
+
+// GetBuildMatrixJobName returns the job name suffixed with a hash of the matrix key, shortened to stay a valid kubernetes name
+func GetBuildMatrixJobName(jobName *string, key string) string {
+	hash := sha256.Sum256([]byte(key))
+	suffix := hex.EncodeToString(hash[:])[:8]
+
+	base := "build"
+	if jobName != nil && *jobName != "" {
+		base = *jobName
+	}
+
+	maxBaseLength := buildMatrixMaxJobName - len(suffix) - 1
+	if len(base) > maxBaseLength {
+		// shorten the middle to keep the build or release id at the end unique
+		id := ""
+		if i := strings.LastIndex(base, "-"); i > 0 && len(base)-i < maxBaseLength {
+			id = base[i:]
+		}
+		base = strings.TrimRight(base[:maxBaseLength-len(id)], "-") + id
+	}
+
+	return fmt.Sprintf("%v-%v", base, suffix)
+}
+
+// GroupBuildsByMatrix returns the builds of the same revision of a pipeline together, so the results of all matrix combinations can be shown as one
+func GroupBuildsByMatrix(builds []*Build) map[string][]*Build {
+	groups := map[string][]*Build{}
+	for _, b := range builds {
+		if b == nil {
+			continue
+		}
+		key := fmt.Sprintf("%v@%v", b.GetFullRepoPath(), b.RepoRevision)
+		groups[key] = append(groups[key], b)
+	}
+	for _, g := range groups {
+		sort.SliceStable(g, func(i, j int) bool {
+			return g[i].MatrixKey < g[j].MatrixKey
+		})
+	}
+
+	return groups
+}
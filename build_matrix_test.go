@@ -0,0 +1,244 @@
+package contracts
+
+import (
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestBuildMatrixGetCombinations(t *testing.T) {
+	t.Run("ReturnsCartesianProductWithoutExcludedAndWithIncludedCombinations", func(t *testing.T) {
+
+		var matrix BuildMatrix
+		err := yaml.Unmarshal([]byte(`
+axes:
+- name: go-version
+  values:
+  - "1.16"
+  - "1.17"
+- name: track
+  values:
+  - stable
+  - beta
+exclude:
+- go-version: "1.16"
+  track: beta
+include:
+- go-version: "1.18"
+  track: dev
+- go-version: "1.17"
+  track: stable
+`), &matrix)
+		if !assert.Nil(t, err) || !assert.Nil(t, matrix.Validate()) {
+			return
+		}
+
+		// act
+		combinations := matrix.GetCombinations()
+
+		keys := []string{}
+		for _, c := range combinations {
+			keys = append(keys, GetBuildMatrixKey(c))
+		}
+		assert.Equal(t, []string{
+			"go-version=1.16,track=stable",
+			"go-version=1.17,track=stable",
+			"go-version=1.17,track=beta",
+			"go-version=1.18,track=dev",
+		}, keys)
+	})
+
+	t.Run("DoesNotExcludeIncludedCombinations", func(t *testing.T) {
+
+		matrix := BuildMatrix{
+			Axes: []*BuildMatrixAxis{
+				{Name: "go-version", Values: []string{"1.16", "1.17"}},
+			},
+			Include: []map[string]string{{"go-version": "1.18"}},
+			Exclude: []map[string]string{{"go-version": "1.16"}, {"go-version": "1.18"}},
+		}
+
+		// act
+		combinations := matrix.GetCombinations()
+
+		assert.Equal(t, []map[string]string{{"go-version": "1.17"}, {"go-version": "1.18"}}, combinations)
+	})
+}
+
+func TestBuildMatrixValidate(t *testing.T) {
+	t.Run("ReturnsErrorForInvalidMatrices", func(t *testing.T) {
+
+		matrices := []BuildMatrix{
+			{},
+			{Axes: []*BuildMatrixAxis{{Name: "go"}}},
+			{Axes: []*BuildMatrixAxis{{Name: "go", Values: []string{"1.17"}}, {Name: "go", Values: []string{"1.18"}}}},
+			{Axes: []*BuildMatrixAxis{{Name: "go", Values: []string{"1.17", "1.17"}}}},
+			{Axes: []*BuildMatrixAxis{{Name: "go", Values: []string{"1.17"}}}, Exclude: []map[string]string{{"node": "16"}}},
+			{Axes: []*BuildMatrixAxis{{Name: "go", Values: []string{"1.17"}}, {Name: "os", Values: []string{"linux"}}}, Include: []map[string]string{{"go": "1.18"}}},
+		}
+
+		for _, m := range matrices {
+			// act
+			err := m.Validate()
+
+			assert.NotNil(t, err)
+		}
+	})
+}
+
+func TestExpandBuildMatrix(t *testing.T) {
+	t.Run("ReturnsConfigPerCombinationWithJobNameEnvVarsAndMatrixKey", func(t *testing.T) {
+
+		jobName := "build-estafette-estafette-ci-api-123456"
+		track := "stable"
+		config := BuilderConfig{
+			JobType: JobTypeBuild,
+			Build:   &Build{ID: "123456", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", RepoRevision: "5a2d5b2c"},
+			Track:   &track,
+			JobName: &jobName,
+			Manifest: &manifest.EstafetteManifest{
+				Builder:       manifest.EstafetteBuilder{Track: "stable", OperatingSystem: manifest.OperatingSystemLinux},
+				GlobalEnvVars: map[string]string{"CGO_ENABLED": "0"},
+			},
+			ManifestPreferences: &manifest.EstafetteManifestPreferences{
+				BuilderOperatingSystems: []manifest.OperatingSystem{manifest.OperatingSystemLinux},
+				BuilderTracksPerOperatingSystem: map[manifest.OperatingSystem][]string{
+					manifest.OperatingSystemLinux: {"stable", "beta"},
+				},
+			},
+		}
+		matrix := BuildMatrix{
+			Axes: []*BuildMatrixAxis{
+				{Name: "go-version", Values: []string{"1.16", "1.17"}},
+				{Name: "track", Values: []string{"stable", "beta"}},
+			},
+		}
+
+		// act
+		configs, err := ExpandBuildMatrix(config, matrix)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 4, len(configs)) {
+			return
+		}
+		jobNames := map[string]bool{}
+		for _, c := range configs {
+			jobNames[*c.JobName] = true
+			assert.LessOrEqual(t, len(*c.JobName), 63)
+			assert.Equal(t, "0", c.Manifest.GlobalEnvVars["CGO_ENABLED"])
+			assert.Equal(t, c.Build.Matrix["go-version"], c.Manifest.GlobalEnvVars["ESTAFETTE_MATRIX_GO_VERSION"])
+			assert.Equal(t, c.Build.Matrix["track"], *c.Track)
+			assert.Equal(t, c.Build.Matrix["track"], c.Manifest.Builder.Track)
+		}
+		assert.Equal(t, 4, len(jobNames))
+		assert.Equal(t, "go-version=1.16,track=beta", configs[1].Build.MatrixKey)
+		assert.Equal(t, "123456", configs[1].Build.ID)
+
+		// the original config is left untouched
+		assert.Equal(t, "build-estafette-estafette-ci-api-123456", *config.JobName)
+		assert.Equal(t, "stable", *config.Track)
+		assert.Equal(t, map[string]string{"CGO_ENABLED": "0"}, config.Manifest.GlobalEnvVars)
+		assert.Equal(t, "", config.Build.MatrixKey)
+	})
+
+	t.Run("ReturnsErrorForTrackNotAllowedForOperatingSystem", func(t *testing.T) {
+
+		config := BuilderConfig{
+			Manifest: &manifest.EstafetteManifest{
+				Builder: manifest.EstafetteBuilder{Track: "stable", OperatingSystem: manifest.OperatingSystemLinux},
+			},
+			ManifestPreferences: &manifest.EstafetteManifestPreferences{
+				BuilderOperatingSystems: []manifest.OperatingSystem{manifest.OperatingSystemLinux, manifest.OperatingSystemWindows},
+				BuilderTracksPerOperatingSystem: map[manifest.OperatingSystem][]string{
+					manifest.OperatingSystemLinux:   {"stable", "beta", "dev"},
+					manifest.OperatingSystemWindows: {"windowsservercore-1809"},
+				},
+			},
+		}
+		matrix := BuildMatrix{
+			Axes: []*BuildMatrixAxis{
+				{Name: "os", Values: []string{"linux", "windows"}},
+				{Name: "track", Values: []string{"stable"}},
+			},
+		}
+
+		// act
+		_, err := ExpandBuildMatrix(config, matrix)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsConfigsThatDoNotShareStages", func(t *testing.T) {
+
+		config := BuilderConfig{
+			Manifest: &manifest.EstafetteManifest{
+				Stages: []*manifest.EstafetteStage{
+					{Name: "build", ContainerImage: "golang:1.17-alpine", EnvVars: map[string]string{"GOOS": "linux"}},
+				},
+			},
+		}
+		matrix := BuildMatrix{
+			Axes: []*BuildMatrixAxis{
+				{Name: "go-version", Values: []string{"1.16", "1.17"}},
+			},
+		}
+
+		// act
+		configs, err := ExpandBuildMatrix(config, matrix)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(configs)) {
+			return
+		}
+		configs[0].Manifest.Stages[0].EnvVars["GOOS"] = "windows"
+		assert.Equal(t, "linux", configs[1].Manifest.Stages[0].EnvVars["GOOS"])
+		assert.Equal(t, "linux", config.Manifest.Stages[0].EnvVars["GOOS"])
+	})
+}
+
+func TestGetBuildMatrixJobName(t *testing.T) {
+	t.Run("ReturnsShortenedJobNameWithHashSuffix", func(t *testing.T) {
+
+		jobName := "build-estafette-estafette-ci-api-with-a-very-long-repository-name-123456"
+
+		// act
+		matrixJobName := GetBuildMatrixJobName(&jobName, "go-version=1.17")
+
+		assert.Equal(t, 63, len(matrixJobName))
+		assert.Equal(t, matrixJobName, GetBuildMatrixJobName(&jobName, "go-version=1.17"))
+		assert.NotEqual(t, matrixJobName, GetBuildMatrixJobName(&jobName, "go-version=1.16"))
+	})
+
+	t.Run("KeepsIdOfShortenedJobName", func(t *testing.T) {
+
+		jobName := "build-estafette-estafette-ci-api-with-a-very-long-repository-name-123456"
+		otherJobName := "build-estafette-estafette-ci-api-with-a-very-long-repository-name-123457"
+
+		// act
+		matrixJobName := GetBuildMatrixJobName(&jobName, "go-version=1.17")
+
+		assert.Equal(t, "build-estafette-estafette-ci-api-with-a-very-lo-123456-", matrixJobName[:55])
+		assert.NotEqual(t, matrixJobName, GetBuildMatrixJobName(&otherJobName, "go-version=1.17"))
+	})
+}
+
+func TestGroupBuildsByMatrix(t *testing.T) {
+	t.Run("ReturnsBuildsPerRevisionSortedByMatrixKey", func(t *testing.T) {
+
+		builds := []*Build{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", RepoRevision: "a", MatrixKey: "go=1.17"},
+			{ID: "2", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", RepoRevision: "a", MatrixKey: "go=1.16"},
+			{ID: "3", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", RepoRevision: "b", MatrixKey: "go=1.16"},
+		}
+
+		// act
+		groups := GroupBuildsByMatrix(builds)
+
+		assert.Equal(t, 2, len(groups))
+		group := groups["github.com/estafette/estafette-ci-api@a"]
+		if assert.Equal(t, 2, len(group)) {
+			assert.Equal(t, "2", group[0].ID)
+			assert.Equal(t, "1", group[1].ID)
+		}
+	})
+}
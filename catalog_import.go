@@ -0,0 +1,308 @@
+package contracts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	CatalogEntityKeyPipeline      = "pipeline"
+	CatalogEntityKeyReleaseTarget = "release-target"
+	CatalogEntityKeyNamespace     = "namespace"
+	CatalogEntityKeyDeployment    = "deployment"
+	CatalogEntityKeyService       = "service"
+)
+
+// CatalogReconciliation lists the changes needed to turn the existing entities into the imported entities
+type CatalogReconciliation struct {
+	Creates []*CatalogEntity `json:"creates,omitempty"`
+	Updates []*CatalogEntity `json:"updates,omitempty"`
+	Deletes []*CatalogEntity `json:"deletes,omitempty"`
+}
+
+// catalogFileEntity is the nested yaml representation of an entity in a plain catalog file
+type catalogFileEntity struct {
+	Key            string                 `yaml:"key"`
+	Value          string                 `yaml:"value"`
+	LinkedPipeline string                 `yaml:"linkedPipeline,omitempty"`
+	Labels         map[string]string      `yaml:"labels,omitempty"`
+	Metadata       map[string]interface{} `yaml:"metadata,omitempty"`
+	Children       []*catalogFileEntity   `yaml:"children,omitempty"`
+}
+
+// kubernetesObject has the fields of any kubernetes object needed to import it into the catalog
+type kubernetesObject struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name      string            `yaml:"name"`
+		Namespace string            `yaml:"namespace"`
+		Labels    map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+}
+
+// ImportCatalogEntitiesFromManifest returns an entity for the pipeline with the manifest's labels, and a child entity per release target
+func ImportCatalogEntitiesFromManifest(fullRepoPath string, mft manifest.EstafetteManifest, parentKey, parentValue string) []*CatalogEntity {
+
+	entities := []*CatalogEntity{
+		{
+			ParentKey:      parentKey,
+			ParentValue:    parentValue,
+			Key:            CatalogEntityKeyPipeline,
+			Value:          fullRepoPath,
+			LinkedPipeline: fullRepoPath,
+			Labels:         getSortedLabels(mft.Labels),
+		},
+	}
+
+	for _, r := range mft.Releases {
+		if r == nil {
+			continue
+		}
+
+		entity := &CatalogEntity{
+			ParentKey:      CatalogEntityKeyPipeline,
+			ParentValue:    fullRepoPath,
+			Key:            CatalogEntityKeyReleaseTarget,
+			Value:          fmt.Sprintf("%v/%v", fullRepoPath, r.Name),
+			LinkedPipeline: fullRepoPath,
+			Metadata: map[string]interface{}{
+				"name": r.Name,
+			},
+		}
+		if len(r.Actions) > 0 {
+			actions := []interface{}{}
+			for _, a := range r.Actions {
+				actions = append(actions, a.Name)
+			}
+			entity.Metadata["actions"] = actions
+		}
+
+		entities = append(entities, entity)
+	}
+
+	return entities
+}
+
+// ImportCatalogEntitiesFromKubernetesManifests returns entities for the namespaces, deployments and services in multi-document kubernetes yaml
+func ImportCatalogEntitiesFromKubernetesManifests(data []byte, linkedPipeline, parentKey, parentValue string) ([]*CatalogEntity, error) {
+
+	entities := []*CatalogEntity{}
+	namespaces := map[string]*CatalogEntity{}
+
+	getNamespace := func(name string, labels map[string]string) *CatalogEntity {
+		if ns, ok := namespaces[name]; ok {
+			if len(labels) > 0 {
+				ns.Labels = getSortedLabels(labels)
+			}
+			return ns
+		}
+		// qualify the value with the parent value, so the same namespace in different clusters gets a different value
+		value := name
+		if parentValue != "" {
+			value = fmt.Sprintf("%v/%v", parentValue, name)
+		}
+		ns := &CatalogEntity{
+			ParentKey:      parentKey,
+			ParentValue:    parentValue,
+			Key:            CatalogEntityKeyNamespace,
+			Value:          value,
+			LinkedPipeline: linkedPipeline,
+			Labels:         getSortedLabels(labels),
+			Metadata: map[string]interface{}{
+				"name": name,
+			},
+		}
+		namespaces[name] = ns
+		entities = append(entities, ns)
+		return ns
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var object kubernetesObject
+		err := decoder.Decode(&object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Kubernetes manifests can't be unmarshalled: %w", err)
+		}
+
+		namespace := object.Metadata.Namespace
+		if namespace == "" {
+			namespace = "default"
+		}
+
+		switch object.Kind {
+		case "Namespace":
+			getNamespace(object.Metadata.Name, object.Metadata.Labels)
+
+		case "Deployment", "Service":
+			ns := getNamespace(namespace, nil)
+			key := CatalogEntityKeyDeployment
+			if object.Kind == "Service" {
+				key = CatalogEntityKeyService
+			}
+			entities = append(entities, &CatalogEntity{
+				ParentKey:      ns.Key,
+				ParentValue:    ns.Value,
+				Key:            key,
+				Value:          fmt.Sprintf("%v/%v", ns.Value, object.Metadata.Name),
+				LinkedPipeline: linkedPipeline,
+				Labels:         getSortedLabels(object.Metadata.Labels),
+				Metadata: map[string]interface{}{
+					"name": object.Metadata.Name,
+				},
+			})
+		}
+	}
+
+	return entities, nil
+}
+
+// ImportCatalogEntitiesFromYaml returns the entities in a plain catalog file, where entities list their children
+func ImportCatalogEntitiesFromYaml(data []byte) ([]*CatalogEntity, error) {
+
+	var fileEntities []*catalogFileEntity
+	if err := yaml.Unmarshal(data, &fileEntities); err != nil {
+		return nil, fmt.Errorf("Catalog file can't be unmarshalled: %w", err)
+	}
+
+	entities := []*CatalogEntity{}
+
+	var flatten func(fileEntities []*catalogFileEntity, parentKey, parentValue string) error
+	flatten = func(fileEntities []*catalogFileEntity, parentKey, parentValue string) error {
+		for _, fe := range fileEntities {
+			if fe.Key == "" || fe.Value == "" {
+				return fmt.Errorf("Catalog file entity under %v=%v needs a key and value", parentKey, parentValue)
+			}
+
+			entity := &CatalogEntity{
+				ParentKey:      parentKey,
+				ParentValue:    parentValue,
+				Key:            fe.Key,
+				Value:          fe.Value,
+				LinkedPipeline: fe.LinkedPipeline,
+				Labels:         getSortedLabels(fe.Labels),
+			}
+			if len(fe.Metadata) > 0 {
+				entity.Metadata = cleanUpStringMap(fe.Metadata)
+			}
+			entities = append(entities, entity)
+
+			if err := flatten(fe.Children, fe.Key, fe.Value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := flatten(fileEntities, "", ""); err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// ReconcileCatalogEntities compares imported entities with the existing entities by parent, key and value, deleting every existing entity that isn't imported
+func ReconcileCatalogEntities(existing, imported []*CatalogEntity) CatalogReconciliation {
+
+	reconciliation := CatalogReconciliation{
+		Creates: []*CatalogEntity{},
+		Updates: []*CatalogEntity{},
+		Deletes: []*CatalogEntity{},
+	}
+
+	existingByKey := map[catalogNodeKey]*CatalogEntity{}
+	for _, e := range existing {
+		if e != nil {
+			existingByKey[getCatalogNodeKey(e)] = e
+		}
+	}
+
+	importedKeys := map[catalogNodeKey]bool{}
+	for _, i := range imported {
+		if i == nil {
+			continue
+		}
+		key := getCatalogNodeKey(i)
+		if importedKeys[key] {
+			continue
+		}
+		importedKeys[key] = true
+
+		e, ok := existingByKey[key]
+		if !ok {
+			reconciliation.Creates = append(reconciliation.Creates, i)
+			continue
+		}
+		if !isCatalogEntityContentEqual(e, i) {
+			updated := *i
+			updated.ID = e.ID
+			updated.InsertedAt = e.InsertedAt
+			updated.UpdatedAt = e.UpdatedAt
+			reconciliation.Updates = append(reconciliation.Updates, &updated)
+		}
+	}
+
+	for _, e := range existing {
+		if e != nil && !importedKeys[getCatalogNodeKey(e)] {
+			reconciliation.Deletes = append(reconciliation.Deletes, e)
+		}
+	}
+
+	return reconciliation
+}
+
+// isCatalogEntityContentEqual compares entities ignoring id and timestamps, with metadata compared as json
+func isCatalogEntityContentEqual(a, b *CatalogEntity) bool {
+	if a.ParentKey != b.ParentKey || a.ParentValue != b.ParentValue || a.LinkedPipeline != b.LinkedPipeline {
+		return false
+	}
+
+	aLabels, _ := json.Marshal(getSortedLabelSlice(a.Labels))
+	bLabels, _ := json.Marshal(getSortedLabelSlice(b.Labels))
+	if !bytes.Equal(aLabels, bLabels) {
+		return false
+	}
+
+	if len(a.Metadata) == 0 && len(b.Metadata) == 0 {
+		return true
+	}
+	aMetadata, _ := json.Marshal(a.Metadata)
+	bMetadata, _ := json.Marshal(b.Metadata)
+
+	return bytes.Equal(aMetadata, bMetadata)
+}
+
+func getSortedLabels(labels map[string]string) []Label {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	sortedLabels := []Label{}
+	for k, v := range labels {
+		sortedLabels = append(sortedLabels, Label{Key: k, Value: v})
+	}
+
+	return getSortedLabelSlice(sortedLabels)
+}
+
+func getSortedLabelSlice(labels []Label) []Label {
+	sortedLabels := append([]Label{}, labels...)
+	sort.Slice(sortedLabels, func(i, j int) bool {
+		if sortedLabels[i].Key != sortedLabels[j].Key {
+			return sortedLabels[i].Key < sortedLabels[j].Key
+		}
+		return sortedLabels[i].Value < sortedLabels[j].Value
+	})
+
+	return sortedLabels
+}
@@ -0,0 +1,210 @@
+package contracts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCatalogEntitiesFromManifest(t *testing.T) {
+	t.Run("ReturnsPipelineEntityWithLabelsAndReleaseTargetChildren", func(t *testing.T) {
+
+		mft, err := manifest.ReadManifest(nil, `
+labels:
+  app: payments-api
+  team: payments
+
+stages:
+  build:
+    image: golang:1.17-alpine
+    commands:
+    - go build
+
+releases:
+  staging:
+    stages:
+      deploy:
+        image: extensions/gke:stable
+  production:
+    actions:
+    - name: deploy-canary
+    - name: deploy-stable
+    stages:
+      deploy:
+        image: extensions/gke:stable
+`, false)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		entities := ImportCatalogEntitiesFromManifest("github.com/estafette/payments-api", mft, "team", "payments")
+
+		if !assert.Equal(t, 3, len(entities)) {
+			return
+		}
+		assert.Equal(t, &CatalogEntity{
+			ParentKey:      "team",
+			ParentValue:    "payments",
+			Key:            "pipeline",
+			Value:          "github.com/estafette/payments-api",
+			LinkedPipeline: "github.com/estafette/payments-api",
+			Labels:         []Label{{Key: "app", Value: "payments-api"}, {Key: "team", Value: "payments"}},
+		}, entities[0])
+		assert.Equal(t, "pipeline", entities[1].ParentKey)
+		assert.Equal(t, "github.com/estafette/payments-api", entities[1].ParentValue)
+		assert.Equal(t, "release-target", entities[1].Key)
+		assert.Equal(t, "github.com/estafette/payments-api/staging", entities[1].Value)
+		assert.Equal(t, "github.com/estafette/payments-api/production", entities[2].Value)
+		assert.Equal(t, []interface{}{"deploy-canary", "deploy-stable"}, entities[2].Metadata["actions"])
+
+		tree := NewCatalogTree(entities)
+		node, err := tree.GetNodeByPath("pipeline=github.com/estafette/payments-api/release-target=github.com/estafette/payments-api/production")
+		assert.Nil(t, err)
+		assert.NotNil(t, node)
+	})
+}
+
+func TestImportCatalogEntitiesFromKubernetesManifests(t *testing.T) {
+	t.Run("ReturnsNamespacesDeploymentsAndServices", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("kubernetes-manifests-import-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		entities, err := ImportCatalogEntitiesFromKubernetesManifests(bytes, "github.com/estafette/payments-api", "kubernetes-cluster", "prod-europe-west1")
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 5, len(entities)) {
+			return
+		}
+		assert.Equal(t, "namespace", entities[0].Key)
+		assert.Equal(t, "prod-europe-west1/payments", entities[0].Value)
+		assert.Equal(t, "kubernetes-cluster", entities[0].ParentKey)
+		assert.Equal(t, "prod-europe-west1", entities[0].ParentValue)
+		assert.Equal(t, []Label{{Key: "team", Value: "payments"}}, entities[0].Labels)
+		assert.Equal(t, "deployment", entities[1].Key)
+		assert.Equal(t, "prod-europe-west1/payments/payments-api", entities[1].Value)
+		assert.Equal(t, "namespace", entities[1].ParentKey)
+		assert.Equal(t, "prod-europe-west1/payments", entities[1].ParentValue)
+		assert.Equal(t, "github.com/estafette/payments-api", entities[1].LinkedPipeline)
+		assert.Equal(t, "service", entities[2].Key)
+		assert.Equal(t, "prod-europe-west1/payments/payments-api", entities[2].Value)
+		assert.Equal(t, "namespace", entities[3].Key)
+		assert.Equal(t, "prod-europe-west1/default", entities[3].Value)
+		assert.Equal(t, "prod-europe-west1/default/payments-api-metrics", entities[4].Value)
+	})
+
+	t.Run("ReturnsDifferentEntitiesForSameManifestsInDifferentClusters", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("kubernetes-manifests-import-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		entities := []*CatalogEntity{
+			{Key: "kubernetes-cluster", Value: "prod-europe-west1"},
+			{Key: "kubernetes-cluster", Value: "prod-europe-west4"},
+		}
+
+		// act
+		west1, err := ImportCatalogEntitiesFromKubernetesManifests(bytes, "github.com/estafette/payments-api", "kubernetes-cluster", "prod-europe-west1")
+		if !assert.Nil(t, err) {
+			return
+		}
+		west4, err := ImportCatalogEntitiesFromKubernetesManifests(bytes, "github.com/estafette/payments-api", "kubernetes-cluster", "prod-europe-west4")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		tree := NewCatalogTree(append(append(entities, west1...), west4...))
+		assert.Equal(t, 0, len(tree.Duplicates))
+		assert.Equal(t, 0, len(tree.AmbiguousParents))
+		assert.Equal(t, 0, len(tree.Orphans))
+		node, err := tree.GetNodeByPath("kubernetes-cluster=prod-europe-west4/namespace=prod-europe-west4/payments/deployment=prod-europe-west4/payments/payments-api")
+		assert.Nil(t, err)
+		assert.NotNil(t, node)
+	})
+}
+
+func TestImportCatalogEntitiesFromYaml(t *testing.T) {
+	t.Run("ReturnsFlattenedEntitiesWithParents", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("catalog-import-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		entities, err := ImportCatalogEntitiesFromYaml(bytes)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 4, len(entities)) {
+			return
+		}
+		assert.Equal(t, "", entities[0].ParentKey)
+		assert.Equal(t, []Label{{Key: "team", Value: "platform"}}, entities[0].Labels)
+		assert.Equal(t, "cloud", entities[1].ParentKey)
+		assert.Equal(t, "gcp", entities[1].ParentValue)
+		assert.Equal(t, "project", entities[2].ParentKey)
+		assert.Equal(t, "prod", entities[2].ParentValue)
+		assert.Equal(t, "github.com/estafette/payments-api", entities[3].LinkedPipeline)
+
+		_, err = json.Marshal(entities)
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorForEntityWithoutValue", func(t *testing.T) {
+
+		// act
+		_, err := ImportCatalogEntitiesFromYaml([]byte("- key: cloud\n"))
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestReconcileCatalogEntities(t *testing.T) {
+	t.Run("ReturnsCreatesUpdatesAndDeletes", func(t *testing.T) {
+
+		var existingMetadata map[string]interface{}
+		err := json.Unmarshal([]byte(`{"projectNumber":123456789}`), &existingMetadata)
+		if !assert.Nil(t, err) {
+			return
+		}
+		existing := []*CatalogEntity{
+			{ID: "1", Key: "cloud", Value: "gcp"},
+			{ID: "2", ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod", Metadata: existingMetadata},
+			{ID: "3", ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1"},
+			{ID: "4", ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west4"},
+		}
+		bytes, err := ioutil.ReadFile("catalog-import-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		imported, err := ImportCatalogEntitiesFromYaml(bytes)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		reconciliation := ReconcileCatalogEntities(existing, imported)
+
+		if !assert.Equal(t, 1, len(reconciliation.Creates)) {
+			return
+		}
+		assert.Equal(t, "team", reconciliation.Creates[0].Key)
+		if !assert.Equal(t, 2, len(reconciliation.Updates)) {
+			return
+		}
+		assert.Equal(t, "1", reconciliation.Updates[0].ID)
+		assert.Equal(t, []Label{{Key: "team", Value: "platform"}}, reconciliation.Updates[0].Labels)
+		assert.Equal(t, "3", reconciliation.Updates[1].ID)
+		assert.Equal(t, "europe-west1", reconciliation.Updates[1].Metadata["region"])
+		if !assert.Equal(t, 1, len(reconciliation.Deletes)) {
+			return
+		}
+		assert.Equal(t, "4", reconciliation.Deletes[0].ID)
+	})
+}
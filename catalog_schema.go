@@ -0,0 +1,259 @@
+package contracts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+type CatalogPropertyType string
+
+const (
+	CatalogPropertyTypeUnknown CatalogPropertyType = ""
+	CatalogPropertyTypeString  CatalogPropertyType = "string"
+	CatalogPropertyTypeNumber  CatalogPropertyType = "number"
+	CatalogPropertyTypeInteger CatalogPropertyType = "integer"
+	CatalogPropertyTypeBoolean CatalogPropertyType = "boolean"
+	CatalogPropertyTypeObject  CatalogPropertyType = "object"
+	CatalogPropertyTypeArray   CatalogPropertyType = "array"
+)
+
+// CatalogEntitySchema describes the metadata and parents allowed for catalog entities with a specific key
+type CatalogEntitySchema struct {
+	Key               string                            `yaml:"key" json:"key"`
+	AllowedParentKeys []string                          `yaml:"allowedParentKeys,omitempty" json:"allowedParentKeys,omitempty"`
+	Required          []string                          `yaml:"required,omitempty" json:"required,omitempty"`
+	Properties        map[string]*CatalogPropertySchema `yaml:"properties,omitempty" json:"properties,omitempty"`
+}
+
+// CatalogPropertySchema describes a single metadata property and its nested values
+type CatalogPropertySchema struct {
+	Type       CatalogPropertyType               `yaml:"type,omitempty" json:"type,omitempty"`
+	Enum       []interface{}                     `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Required   []string                          `yaml:"required,omitempty" json:"required,omitempty"`
+	Properties map[string]*CatalogPropertySchema `yaml:"properties,omitempty" json:"properties,omitempty"`
+	Items      *CatalogPropertySchema            `yaml:"items,omitempty" json:"items,omitempty"`
+}
+
+// CatalogValidationError is a single violation of a catalog entity schema, with the path to the offending field
+type CatalogValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// CatalogSchemaRegistry holds the schemas per catalog entity key
+type CatalogSchemaRegistry struct {
+	schemas map[string]*CatalogEntitySchema
+}
+
+func (e CatalogValidationError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Path, e.Message)
+}
+
+// NewCatalogSchemaRegistry returns a registry with the schemas registered
+func NewCatalogSchemaRegistry(schemas ...*CatalogEntitySchema) (*CatalogSchemaRegistry, error) {
+	registry := &CatalogSchemaRegistry{
+		schemas: map[string]*CatalogEntitySchema{},
+	}
+	for _, s := range schemas {
+		if err := registry.Register(s); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// UnmarshalCatalogEntitySchemas reads a yaml list of catalog entity schemas
+func UnmarshalCatalogEntitySchemas(data []byte) ([]*CatalogEntitySchema, error) {
+	var schemas []*CatalogEntitySchema
+	if err := yaml.Unmarshal(data, &schemas); err != nil {
+		return nil, err
+	}
+
+	return schemas, nil
+}
+
+// Register adds the schema for its key, replacing any schema registered earlier for the same key
+func (r *CatalogSchemaRegistry) Register(schema *CatalogEntitySchema) error {
+	if schema == nil || schema.Key == "" {
+		return fmt.Errorf("Catalog entity schema needs a key")
+	}
+	for name, p := range schema.Properties {
+		if err := p.validateSchema(name); err != nil {
+			return fmt.Errorf("Catalog entity schema for key %v is invalid: %w", schema.Key, err)
+		}
+	}
+
+	r.schemas[schema.Key] = schema
+
+	return nil
+}
+
+// GetSchema returns the schema for a key, or nil if none is registered
+func (r *CatalogSchemaRegistry) GetSchema(key string) *CatalogEntitySchema {
+	return r.schemas[key]
+}
+
+// Validate checks the entity against the schema for its key, if there is one
+func (r *CatalogSchemaRegistry) Validate(entity CatalogEntity) []CatalogValidationError {
+	schema := r.GetSchema(entity.Key)
+	if schema == nil {
+		return []CatalogValidationError{}
+	}
+
+	return schema.Validate(entity)
+}
+
+// Validate checks the entity's parent key and metadata against the schema
+func (s *CatalogEntitySchema) Validate(entity CatalogEntity) []CatalogValidationError {
+	validationErrors := []CatalogValidationError{}
+
+	if len(s.AllowedParentKeys) > 0 && !containsString(s.AllowedParentKeys, entity.ParentKey) {
+		validationErrors = append(validationErrors, CatalogValidationError{
+			Path:    "parentKey",
+			Message: fmt.Sprintf("parent key %q is not allowed for key %v, allowed are %v", entity.ParentKey, s.Key, strings.Join(s.AllowedParentKeys, ", ")),
+		})
+	}
+
+	objectSchema := &CatalogPropertySchema{
+		Type:       CatalogPropertyTypeObject,
+		Required:   s.Required,
+		Properties: s.Properties,
+	}
+	var metadata interface{} = map[string]interface{}{}
+	if entity.Metadata != nil {
+		metadata = entity.Metadata
+	}
+
+	return append(validationErrors, objectSchema.validateValue("metadata", metadata)...)
+}
+
+func (p *CatalogPropertySchema) validateValue(path string, value interface{}) []CatalogValidationError {
+	validationErrors := []CatalogValidationError{}
+
+	switch p.Type {
+	case CatalogPropertyTypeString:
+		if _, ok := value.(string); !ok {
+			return append(validationErrors, CatalogValidationError{Path: path, Message: fmt.Sprintf("expected string, got %T", value)})
+		}
+	case CatalogPropertyTypeNumber:
+		if _, ok := toFloat64(value); !ok {
+			return append(validationErrors, CatalogValidationError{Path: path, Message: fmt.Sprintf("expected number, got %T", value)})
+		}
+	case CatalogPropertyTypeInteger:
+		if f, ok := toFloat64(value); !ok || f != math.Trunc(f) {
+			return append(validationErrors, CatalogValidationError{Path: path, Message: fmt.Sprintf("expected integer, got %v", value)})
+		}
+	case CatalogPropertyTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return append(validationErrors, CatalogValidationError{Path: path, Message: fmt.Sprintf("expected boolean, got %T", value)})
+		}
+	case CatalogPropertyTypeObject:
+		object, ok := toStringMap(value)
+		if !ok {
+			return append(validationErrors, CatalogValidationError{Path: path, Message: fmt.Sprintf("expected object, got %T", value)})
+		}
+		for _, name := range p.Required {
+			if _, ok := object[name]; !ok {
+				validationErrors = append(validationErrors, CatalogValidationError{Path: path + "." + name, Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(p.Properties))
+		for name := range p.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v, ok := object[name]; ok {
+				validationErrors = append(validationErrors, p.Properties[name].validateValue(path+"."+name, v)...)
+			}
+		}
+	case CatalogPropertyTypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			return append(validationErrors, CatalogValidationError{Path: path, Message: fmt.Sprintf("expected array, got %T", value)})
+		}
+		if p.Items != nil {
+			for i, item := range items {
+				validationErrors = append(validationErrors, p.Items.validateValue(fmt.Sprintf("%v[%v]", path, i), item)...)
+			}
+		}
+	}
+
+	if len(p.Enum) > 0 {
+		isAllowed := false
+		allowedValues := []string{}
+		for _, e := range p.Enum {
+			allowedValues = append(allowedValues, fmt.Sprint(e))
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				isAllowed = true
+			}
+		}
+		if !isAllowed {
+			validationErrors = append(validationErrors, CatalogValidationError{Path: path, Message: fmt.Sprintf("value %v is not one of %v", value, strings.Join(allowedValues, ", "))})
+		}
+	}
+
+	return validationErrors
+}
+
+func (p *CatalogPropertySchema) validateSchema(path string) error {
+	if p == nil {
+		return fmt.Errorf("property %v has no schema", path)
+	}
+	switch p.Type {
+	case CatalogPropertyTypeUnknown, CatalogPropertyTypeString, CatalogPropertyTypeNumber, CatalogPropertyTypeInteger, CatalogPropertyTypeBoolean:
+	case CatalogPropertyTypeObject:
+		for name, np := range p.Properties {
+			if err := np.validateSchema(path + "." + name); err != nil {
+				return err
+			}
+		}
+	case CatalogPropertyTypeArray:
+		if p.Items != nil {
+			return p.Items.validateSchema(path + "[]")
+		}
+	default:
+		return fmt.Errorf("property %v has unsupported type %v", path, p.Type)
+	}
+
+	return nil
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	}
+
+	return 0, false
+}
+
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		return cleanUpInterfaceMap(v), true
+	}
+
+	return nil, false
+}
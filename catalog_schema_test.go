@@ -0,0 +1,200 @@
+package contracts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshalCatalogEntitySchemas(t *testing.T) {
+	t.Run("ReturnsSchemasFromYaml", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("catalog-schemas-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		schemas, err := UnmarshalCatalogEntitySchemas(bytes)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 3, len(schemas)) {
+			return
+		}
+		assert.Equal(t, "cloud", schemas[0].Key)
+		assert.Equal(t, []interface{}{"gcp", "aws", "azure"}, schemas[0].Properties["provider"].Enum)
+		assert.Equal(t, []string{"project"}, schemas[1].AllowedParentKeys)
+		assert.Equal(t, CatalogPropertyTypeInteger, schemas[1].Properties["nodePools"].Items.Properties["maxNodes"].Type)
+	})
+}
+
+func TestNewCatalogSchemaRegistry(t *testing.T) {
+	t.Run("ReturnsErrorForUnsupportedPropertyType", func(t *testing.T) {
+
+		schema := &CatalogEntitySchema{
+			Key:        "cloud",
+			Properties: map[string]*CatalogPropertySchema{"provider": {Type: "text"}},
+		}
+
+		// act
+		_, err := NewCatalogSchemaRegistry(schema)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCatalogSchemaRegistryValidate(t *testing.T) {
+	t.Run("ReturnsNoErrorsForValidEntity", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("catalog-schemas-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		schemas, err := UnmarshalCatalogEntitySchemas(bytes)
+		if !assert.Nil(t, err) {
+			return
+		}
+		registry, err := NewCatalogSchemaRegistry(schemas...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		entity := CatalogEntity{
+			ParentKey:   "project",
+			ParentValue: "prod",
+			Key:         "kubernetes-cluster",
+			Value:       "prod-europe-west1",
+			Metadata: map[string]interface{}{
+				"region":    "europe-west1",
+				"nodeCount": 3,
+				"nodePools": []interface{}{
+					map[string]interface{}{"machineType": "n2-standard-8", "maxNodes": 10},
+				},
+			},
+		}
+
+		// act
+		validationErrors := registry.Validate(entity)
+
+		assert.Equal(t, 0, len(validationErrors))
+	})
+
+	t.Run("ReturnsNoErrorsForEntityWithMetadataFromJSON", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("catalog-schemas-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		schemas, err := UnmarshalCatalogEntitySchemas(bytes)
+		if !assert.Nil(t, err) {
+			return
+		}
+		registry, err := NewCatalogSchemaRegistry(schemas...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		var entity CatalogEntity
+		err = json.Unmarshal([]byte(`{"parentKey":"project","parentValue":"prod","key":"database","value":"payments-db","metadata":{"engine":"postgres","sizeGB":12.5}}`), &entity)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		validationErrors := registry.Validate(entity)
+
+		assert.Equal(t, 0, len(validationErrors))
+	})
+
+	t.Run("ReturnsErrorsWithPaths", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("catalog-schemas-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		schemas, err := UnmarshalCatalogEntitySchemas(bytes)
+		if !assert.Nil(t, err) {
+			return
+		}
+		registry, err := NewCatalogSchemaRegistry(schemas...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		entity := CatalogEntity{
+			ParentKey:   "cloud",
+			ParentValue: "gcp",
+			Key:         "kubernetes-cluster",
+			Value:       "prod-europe-west1",
+			Metadata: map[string]interface{}{
+				"nodeCount":   2.5,
+				"autoscaling": "yes",
+				"nodePools": []interface{}{
+					map[string]interface{}{"maxNodes": 10},
+				},
+			},
+		}
+
+		// act
+		validationErrors := registry.Validate(entity)
+
+		paths := []string{}
+		for _, e := range validationErrors {
+			paths = append(paths, e.Path)
+		}
+		assert.Equal(t, []string{"parentKey", "metadata.region", "metadata.autoscaling", "metadata.nodeCount", "metadata.nodePools[0].machineType"}, paths)
+	})
+
+	t.Run("ReturnsErrorForValueNotInEnum", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("catalog-schemas-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		schemas, err := UnmarshalCatalogEntitySchemas(bytes)
+		if !assert.Nil(t, err) {
+			return
+		}
+		registry, err := NewCatalogSchemaRegistry(schemas...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		entity := CatalogEntity{
+			Key:      "cloud",
+			Value:    "gcp",
+			Metadata: map[string]interface{}{"provider": "digitalocean"},
+		}
+
+		// act
+		validationErrors := registry.Validate(entity)
+
+		if !assert.Equal(t, 1, len(validationErrors)) {
+			return
+		}
+		assert.Equal(t, "metadata.provider: value digitalocean is not one of gcp, aws, azure", validationErrors[0].Error())
+	})
+
+	t.Run("ReturnsNoErrorsForKeyWithoutSchema", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("catalog-schemas-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		schemas, err := UnmarshalCatalogEntitySchemas(bytes)
+		if !assert.Nil(t, err) {
+			return
+		}
+		registry, err := NewCatalogSchemaRegistry(schemas...)
+		if !assert.Nil(t, err) {
+			return
+		}
+		entity := CatalogEntity{
+			Key:      "team",
+			Value:    "payments",
+			Metadata: map[string]interface{}{"anything": true},
+		}
+
+		// act
+		validationErrors := registry.Validate(entity)
+
+		assert.Equal(t, 0, len(validationErrors))
+	})
+}
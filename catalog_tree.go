@@ -0,0 +1,317 @@
+package contracts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CatalogTree is an in-memory tree assembled from a flat list of catalog entities
+type CatalogTree struct {
+	Roots []*CatalogNode
+	// Orphans are the nodes whose parent doesn't exist, with their subtrees
+	Orphans []*CatalogNode
+	// AmbiguousParents are the nodes whose parent key and value match more than one entity, with their subtrees
+	AmbiguousParents []*CatalogNode
+	// Cycles are the nodes that are their own ancestor, with the subtrees outside of the cycle
+	Cycles     [][]*CatalogNode
+	Duplicates []*CatalogEntity
+
+	nodes        map[catalogNodeKey]*CatalogNode
+	nodesByLabel map[Label][]*CatalogNode
+}
+
+// CatalogNode is an entity in the catalog tree with links to its parent and children
+type CatalogNode struct {
+	Entity   *CatalogEntity
+	Parent   *CatalogNode
+	Children []*CatalogNode
+}
+
+// CatalogQuery selects the entities matching all of its set fields
+type CatalogQuery struct {
+	// Path selects all entities underneath the entity at the path, for example cloud=gcp/project=prod
+	Path           string  `json:"path,omitempty"`
+	Key            string  `json:"key,omitempty"`
+	Labels         []Label `json:"labels,omitempty"`
+	LinkedPipeline string  `json:"linkedPipeline,omitempty"`
+}
+
+// catalogNodeKey identifies an entity by its parent and its own key and value
+type catalogNodeKey struct {
+	ParentKey   string
+	ParentValue string
+	Key         string
+	Value       string
+}
+
+// NewCatalogTree builds a tree from the entities, detecting orphans, cycles and duplicates
+func NewCatalogTree(entities []*CatalogEntity) *CatalogTree {
+
+	tree := &CatalogTree{
+		Roots:            []*CatalogNode{},
+		Orphans:          []*CatalogNode{},
+		AmbiguousParents: []*CatalogNode{},
+		Cycles:           [][]*CatalogNode{},
+		Duplicates:       []*CatalogEntity{},
+		nodes:            map[catalogNodeKey]*CatalogNode{},
+		nodesByLabel:     map[Label][]*CatalogNode{},
+	}
+
+	// sort to make the tree independent of the order of the entities
+	sortedEntities := make([]*CatalogEntity, 0, len(entities))
+	for _, e := range entities {
+		if e != nil {
+			sortedEntities = append(sortedEntities, e)
+		}
+	}
+	sort.SliceStable(sortedEntities, func(i, j int) bool {
+		a, b := getCatalogNodeKey(sortedEntities[i]), getCatalogNodeKey(sortedEntities[j])
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		if a.ParentKey != b.ParentKey {
+			return a.ParentKey < b.ParentKey
+		}
+		return a.ParentValue < b.ParentValue
+	})
+
+	sortedNodes := []*CatalogNode{}
+	for _, e := range sortedEntities {
+		key := getCatalogNodeKey(e)
+		if _, ok := tree.nodes[key]; ok {
+			tree.Duplicates = append(tree.Duplicates, e)
+			continue
+		}
+		node := &CatalogNode{Entity: e, Children: []*CatalogNode{}}
+		tree.nodes[key] = node
+		label := Label{Key: e.Key, Value: e.Value}
+		tree.nodesByLabel[label] = append(tree.nodesByLabel[label], node)
+		sortedNodes = append(sortedNodes, node)
+	}
+
+	parents := map[*CatalogNode]*CatalogNode{}
+	for _, n := range sortedNodes {
+		if n.Entity.ParentKey == "" {
+			continue
+		}
+		candidates := tree.nodesByLabel[Label{Key: n.Entity.ParentKey, Value: n.Entity.ParentValue}]
+		if len(candidates) == 1 {
+			parents[n] = candidates[0]
+		}
+	}
+
+	// detect cycles by walking up the parents of every node
+	inCycle := map[*CatalogNode]bool{}
+	for _, n := range sortedNodes {
+		path := []*CatalogNode{}
+		positionInPath := map[*CatalogNode]int{}
+		for current := n; current != nil && !inCycle[current]; current = parents[current] {
+			if position, ok := positionInPath[current]; ok {
+				cycle := path[position:]
+				for _, c := range cycle {
+					inCycle[c] = true
+				}
+				tree.Cycles = append(tree.Cycles, cycle)
+				break
+			}
+			positionInPath[current] = len(path)
+			path = append(path, current)
+		}
+	}
+
+	// link nodes to their parents, except for links that close a cycle
+	for _, n := range sortedNodes {
+		if inCycle[n] {
+			continue
+		}
+		if n.Entity.ParentKey == "" {
+			tree.Roots = append(tree.Roots, n)
+			continue
+		}
+
+		parent, ok := parents[n]
+		if !ok {
+			if len(tree.nodesByLabel[Label{Key: n.Entity.ParentKey, Value: n.Entity.ParentValue}]) == 0 {
+				tree.Orphans = append(tree.Orphans, n)
+			} else {
+				tree.AmbiguousParents = append(tree.AmbiguousParents, n)
+			}
+			continue
+		}
+
+		n.Parent = parent
+		parent.Children = append(parent.Children, n)
+	}
+
+	return tree
+}
+
+// GetNode returns the node for the entity with the key and value, or nil if there's no such entity or more than one
+func (t *CatalogTree) GetNode(key, value string) *CatalogNode {
+	nodes := t.nodesByLabel[Label{Key: key, Value: value}]
+	if len(nodes) != 1 {
+		return nil
+	}
+
+	return nodes[0]
+}
+
+// GetNodeByPath returns the node at the end of a path like cloud=gcp/project=prod, where each segment has to be the parent of the next
+func (t *CatalogTree) GetNodeByPath(path string) (*CatalogNode, error) {
+	segments, err := ParseCatalogPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("Catalog path %v is empty", path)
+	}
+
+	last := segments[len(segments)-1]
+	var match *CatalogNode
+	for _, node := range t.nodesByLabel[last] {
+		current := node
+		for i := len(segments) - 2; i >= 0 && current != nil; i-- {
+			current = current.Parent
+			if current != nil && (current.Entity.Key != segments[i].Key || current.Entity.Value != segments[i].Value) {
+				current = nil
+			}
+		}
+		if current == nil {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("Catalog path %v matches more than one entity", path)
+		}
+		match = node
+	}
+
+	return match, nil
+}
+
+// Query returns the entities matching the query, in depth-first order
+func (t *CatalogTree) Query(query CatalogQuery) ([]*CatalogEntity, error) {
+
+	var candidates []*CatalogNode
+	if query.Path != "" {
+		node, err := t.GetNodeByPath(query.Path)
+		if err != nil {
+			return nil, err
+		}
+		if node == nil {
+			return []*CatalogEntity{}, nil
+		}
+		candidates = node.GetDescendants()
+	} else {
+		// orphans, nodes with ambiguous parents and cycles are queried as well, so none of the entities are unreachable
+		tops := append(append([]*CatalogNode{}, t.Roots...), t.Orphans...)
+		tops = append(tops, t.AmbiguousParents...)
+		for _, c := range t.Cycles {
+			tops = append(tops, c...)
+		}
+
+		candidates = []*CatalogNode{}
+		for _, n := range tops {
+			candidates = append(candidates, n)
+			candidates = append(candidates, n.GetDescendants()...)
+		}
+	}
+
+	entities := []*CatalogEntity{}
+	for _, n := range candidates {
+		if query.Key != "" && n.Entity.Key != query.Key {
+			continue
+		}
+		if query.LinkedPipeline != "" && n.Entity.LinkedPipeline != query.LinkedPipeline {
+			continue
+		}
+		if !n.Entity.HasLabels(query.Labels) {
+			continue
+		}
+		entities = append(entities, n.Entity)
+	}
+
+	return entities, nil
+}
+
+// GetDescendants returns all nodes underneath the node in depth-first order
+func (n *CatalogNode) GetDescendants() []*CatalogNode {
+	descendants := []*CatalogNode{}
+	for _, c := range n.Children {
+		descendants = append(descendants, c)
+		descendants = append(descendants, c.GetDescendants()...)
+	}
+
+	return descendants
+}
+
+// GetBreadcrumbs returns the key/value pairs from the root down to and including the node
+func (n *CatalogNode) GetBreadcrumbs() []Label {
+	breadcrumbs := []Label{}
+	for current := n; current != nil; current = current.Parent {
+		breadcrumbs = append([]Label{{Key: current.Entity.Key, Value: current.Entity.Value}}, breadcrumbs...)
+	}
+
+	return breadcrumbs
+}
+
+// GetPath returns the path from the root down to and including the node, for example cloud=gcp/project=prod
+func (n *CatalogNode) GetPath() string {
+	segments := []string{}
+	for _, b := range n.GetBreadcrumbs() {
+		segments = append(segments, fmt.Sprintf("%v=%v", b.Key, b.Value))
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// HasLabels returns true if the entity has all of the labels
+func (ce *CatalogEntity) HasLabels(labels []Label) bool {
+	for _, l := range labels {
+		hasLabel := false
+		for _, el := range ce.Labels {
+			if el.Key == l.Key && el.Value == l.Value {
+				hasLabel = true
+				break
+			}
+		}
+		if !hasLabel {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ParseCatalogPath splits a path like cloud=gcp/project=prod into its key/value segments, where values can contain slashes
+func ParseCatalogPath(path string) ([]Label, error) {
+	segments := []Label{}
+	for _, s := range strings.Split(strings.Trim(path, "/"), "/") {
+		if s == "" {
+			continue
+		}
+		if !strings.Contains(s, "=") && len(segments) > 0 {
+			segments[len(segments)-1].Value += "/" + s
+			continue
+		}
+		keyValue := strings.SplitN(s, "=", 2)
+		if len(keyValue) != 2 || keyValue[0] == "" || keyValue[1] == "" {
+			return nil, fmt.Errorf("Catalog path segment %v is not of format key=value", s)
+		}
+		segments = append(segments, Label{Key: keyValue[0], Value: keyValue[1]})
+	}
+
+	return segments, nil
+}
+
+func getCatalogNodeKey(e *CatalogEntity) catalogNodeKey {
+	return catalogNodeKey{
+		ParentKey:   e.ParentKey,
+		ParentValue: e.ParentValue,
+		Key:         e.Key,
+		Value:       e.Value,
+	}
+}
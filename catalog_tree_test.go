@@ -0,0 +1,317 @@
+package contracts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCatalogTree(t *testing.T) {
+	t.Run("ReturnsTreeWithRootsAndChildren", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+
+		// act
+		tree := NewCatalogTree(entities)
+
+		if !assert.Equal(t, 1, len(tree.Roots)) {
+			return
+		}
+		assert.Equal(t, "gcp", tree.Roots[0].Entity.Value)
+		if !assert.Equal(t, 2, len(tree.Roots[0].Children)) {
+			return
+		}
+		assert.Equal(t, "dev", tree.Roots[0].Children[0].Entity.Value)
+		assert.Equal(t, "prod", tree.Roots[0].Children[1].Entity.Value)
+		assert.Equal(t, 0, len(tree.Orphans))
+		assert.Equal(t, 0, len(tree.Cycles))
+		assert.Equal(t, 0, len(tree.Duplicates))
+	})
+
+	t.Run("ReturnsOrphansWithTheirSubtreesForEntitiesWithMissingParent", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "aws", Key: "account", Value: "prod"},
+			{ParentKey: "account", ParentValue: "prod", Key: "cluster", Value: "eks-prod"},
+		}
+
+		// act
+		tree := NewCatalogTree(entities)
+
+		if !assert.Equal(t, 1, len(tree.Orphans)) {
+			return
+		}
+		assert.Equal(t, "account", tree.Orphans[0].Entity.Key)
+		if !assert.Equal(t, 1, len(tree.Orphans[0].Children)) {
+			return
+		}
+		assert.Equal(t, "eks-prod", tree.Orphans[0].Children[0].Entity.Value)
+	})
+
+	t.Run("ReturnsCyclesForEntitiesThatAreTheirOwnAncestor", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "b", ParentValue: "2", Key: "a", Value: "1"},
+			{ParentKey: "a", ParentValue: "1", Key: "b", Value: "2"},
+			{ParentKey: "b", ParentValue: "2", Key: "c", Value: "3"},
+		}
+
+		// act
+		tree := NewCatalogTree(entities)
+
+		if !assert.Equal(t, 1, len(tree.Cycles)) {
+			return
+		}
+		assert.Equal(t, 2, len(tree.Cycles[0]))
+		assert.Equal(t, 1, len(tree.Roots))
+		assert.Nil(t, tree.GetNode("b", "2").Parent)
+		assert.Equal(t, "2", tree.GetNode("c", "3").Parent.Entity.Value)
+	})
+
+	t.Run("ReturnsSeparateNodesForEntitiesWithSameKeyAndValueUnderDifferentParents", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cluster", Value: "prod"},
+			{Key: "cluster", Value: "dev"},
+			{ParentKey: "cluster", ParentValue: "prod", Key: "namespace", Value: "payments"},
+			{ParentKey: "cluster", ParentValue: "dev", Key: "namespace", Value: "payments"},
+		}
+
+		// act
+		tree := NewCatalogTree(entities)
+
+		assert.Equal(t, 0, len(tree.Duplicates))
+		assert.Nil(t, tree.GetNode("namespace", "payments"))
+		node, err := tree.GetNodeByPath("cluster=dev/namespace=payments")
+		if !assert.Nil(t, err) || !assert.NotNil(t, node) {
+			return
+		}
+		assert.Equal(t, "dev", node.Parent.Entity.Value)
+	})
+
+	t.Run("ReturnsSeparateNodesForKeysAndValuesContainingEqualsSign", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "a", Value: "b=c"},
+			{Key: "a=b", Value: "c"},
+		}
+
+		// act
+		tree := NewCatalogTree(entities)
+
+		assert.Equal(t, 0, len(tree.Duplicates))
+		assert.Equal(t, 2, len(tree.Roots))
+	})
+
+	t.Run("ReturnsAmbiguousParentsForEntitiesWhoseParentMatchesMoreThanOneEntity", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cluster", Value: "prod"},
+			{Key: "cluster", Value: "dev"},
+			{ParentKey: "cluster", ParentValue: "prod", Key: "namespace", Value: "payments"},
+			{ParentKey: "cluster", ParentValue: "dev", Key: "namespace", Value: "payments"},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api"},
+		}
+
+		// act
+		tree := NewCatalogTree(entities)
+
+		if !assert.Equal(t, 1, len(tree.AmbiguousParents)) {
+			return
+		}
+		assert.Equal(t, "payments-api", tree.AmbiguousParents[0].Entity.Value)
+	})
+
+	t.Run("ReturnsDuplicatesForEntitiesWithSameKeyAndValue", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+		entities = append(entities, &CatalogEntity{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"})
+
+		// act
+		tree := NewCatalogTree(entities)
+
+		assert.Equal(t, 1, len(tree.Duplicates))
+		assert.Equal(t, 2, len(tree.Roots[0].Children))
+	})
+}
+
+func TestCatalogTreeQuery(t *testing.T) {
+	t.Run("ReturnsAllEntitiesUnderPath", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+		tree := NewCatalogTree(entities)
+
+		// act
+		entities, err := tree.Query(CatalogQuery{Path: "cloud=gcp/project=prod"})
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 3, len(entities)) {
+			return
+		}
+		assert.Equal(t, "prod-europe-west1", entities[0].Value)
+		assert.Equal(t, "payments", entities[1].Value)
+		assert.Equal(t, "payments-api", entities[2].Value)
+	})
+
+	t.Run("ReturnsNoEntitiesIfPathDoesNotMatchParents", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+		tree := NewCatalogTree(entities)
+
+		// act
+		entities, err := tree.Query(CatalogQuery{Path: "cloud=aws/project=prod"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(entities))
+	})
+
+	t.Run("ReturnsErrorForInvalidPath", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+		tree := NewCatalogTree(entities)
+
+		// act
+		_, err := tree.Query(CatalogQuery{Path: "cloud/project=prod"})
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsEntitiesUnderOrphansAndCycles", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "aws", Key: "account", Value: "prod"},
+			{ParentKey: "account", ParentValue: "prod", Key: "cluster", Value: "eks-prod"},
+			{ParentKey: "b", ParentValue: "2", Key: "a", Value: "1"},
+			{ParentKey: "a", ParentValue: "1", Key: "b", Value: "2"},
+			{ParentKey: "b", ParentValue: "2", Key: "c", Value: "3"},
+		}
+		tree := NewCatalogTree(entities)
+
+		// act
+		result, err := tree.Query(CatalogQuery{})
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 6, len(result)) {
+			return
+		}
+		assert.Equal(t, "gcp", result[0].Value)
+		assert.Equal(t, "prod", result[1].Value)
+		assert.Equal(t, "eks-prod", result[2].Value)
+	})
+
+	t.Run("ReturnsEntitiesWithLabel", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+		tree := NewCatalogTree(entities)
+
+		// act
+		entities, err := tree.Query(CatalogQuery{Labels: []Label{{Key: "team", Value: "payments"}}})
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 3, len(entities)) {
+			return
+		}
+		assert.Equal(t, "payments-db", entities[0].Value)
+		assert.Equal(t, "payments", entities[1].Value)
+		assert.Equal(t, "payments-api", entities[2].Value)
+	})
+
+	t.Run("ReturnsEntitiesLinkedToPipelineUnderPath", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+		tree := NewCatalogTree(entities)
+
+		// act
+		entities, err := tree.Query(CatalogQuery{Path: "cloud=gcp/project=prod", LinkedPipeline: "github.com/estafette/payments-api"})
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(entities)) {
+			return
+		}
+		assert.Equal(t, "payments-api", entities[0].Value)
+	})
+}
+
+func TestCatalogNodeGetBreadcrumbs(t *testing.T) {
+	t.Run("ReturnsKeyValuePairsFromRootToNode", func(t *testing.T) {
+
+		entities := []*CatalogEntity{
+			{Key: "cloud", Value: "gcp"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "prod"},
+			{ParentKey: "cloud", ParentValue: "gcp", Key: "project", Value: "dev"},
+			{ParentKey: "project", ParentValue: "prod", Key: "kubernetes-cluster", Value: "prod-europe-west1", Labels: []Label{{Key: "team", Value: "platform"}}},
+			{ParentKey: "kubernetes-cluster", ParentValue: "prod-europe-west1", Key: "namespace", Value: "payments", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "namespace", ParentValue: "payments", Key: "deployment", Value: "payments-api", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+			{ParentKey: "project", ParentValue: "dev", Key: "database", Value: "payments-db", LinkedPipeline: "github.com/estafette/payments-api", Labels: []Label{{Key: "team", Value: "payments"}}},
+		}
+		tree := NewCatalogTree(entities)
+		node := tree.GetNode("deployment", "payments-api")
+
+		// act
+		breadcrumbs := node.GetBreadcrumbs()
+
+		assert.Equal(t, []Label{
+			{Key: "cloud", Value: "gcp"},
+			{Key: "project", Value: "prod"},
+			{Key: "kubernetes-cluster", Value: "prod-europe-west1"},
+			{Key: "namespace", Value: "payments"},
+			{Key: "deployment", Value: "payments-api"},
+		}, breadcrumbs)
+		assert.Equal(t, "cloud=gcp/project=prod/kubernetes-cluster=prod-europe-west1/namespace=payments/deployment=payments-api", node.GetPath())
+	})
+}
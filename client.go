@@ -1,15 +1,294 @@
 package contracts
 
-import "time"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
 
 // Client represents a client application registered with Estafette
 type Client struct {
-	ID            string          `json:"id,omitempty"`
-	Active        bool            `json:"active,omitempty"`
-	Name          string          `json:"name,omitempty"`
-	ClientID      string          `json:"clientID,omitempty"`
-	ClientSecret  string          `json:"clientSecret,omitempty"`
-	Roles         []*string       `json:"roles,omitempty"`
-	Organizations []*Organization `json:"organizations,omitempty"`
-	CreatedAt     *time.Time      `json:"createdAt,omitempty"`
+	ID            string                `json:"id,omitempty"`
+	Active        bool                  `json:"active,omitempty"`
+	Name          string                `json:"name,omitempty"`
+	ClientID      string                `json:"clientID,omitempty"`
+	ClientSecret  string                `json:"clientSecret,omitempty"`
+	Secrets       []*HashedClientSecret `json:"secrets,omitempty"`
+	Scopes        []ClientScope         `json:"scopes,omitempty"`
+	Roles         []*string             `json:"roles,omitempty"`
+	Organizations []*Organization       `json:"organizations,omitempty"`
+	CreatedAt     *time.Time            `json:"createdAt,omitempty"`
+}
+
+// HashedClientSecret represents one of the hashed secrets of a client
+type HashedClientSecret struct {
+	ID        string     `json:"id,omitempty"`
+	Hash      string     `json:"hash,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type ClientSecretHashAlgorithm string
+
+const (
+	ClientSecretHashAlgorithmUnknown  ClientSecretHashAlgorithm = ""
+	ClientSecretHashAlgorithmArgon2id ClientSecretHashAlgorithm = "argon2id"
+	ClientSecretHashAlgorithmBcrypt   ClientSecretHashAlgorithm = "bcrypt"
+)
+
+type ClientScope string
+
+const (
+	ClientScopeAll            ClientScope = "*"
+	ClientScopePipelinesRead  ClientScope = "pipelines:read"
+	ClientScopePipelinesWrite ClientScope = "pipelines:write"
+	ClientScopeBuildsRead     ClientScope = "builds:read"
+	ClientScopeBuildsWrite    ClientScope = "builds:write"
+	ClientScopeReleasesRead   ClientScope = "releases:read"
+	ClientScopeReleasesWrite  ClientScope = "releases:write"
+	ClientScopeBotsRead       ClientScope = "bots:read"
+	ClientScopeBotsWrite      ClientScope = "bots:write"
+	ClientScopeCatalogRead    ClientScope = "catalog:read"
+	ClientScopeCatalogWrite   ClientScope = "catalog:write"
+)
+
+const (
+	clientSecretLength = 32
+
+	argon2idTime    = 1
+	argon2idMemory  = 64 * 1024
+	argon2idThreads = 4
+	argon2idKeyLen  = 32
+	argon2idSaltLen = 16
+
+	argon2idMaxTime   = 16
+	argon2idMaxMemory = 1024 * 1024
+)
+
+// GenerateClientSecret returns a new random client secret
+func GenerateClientSecret() (string, error) {
+	bytes := make([]byte, clientSecretLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashClientSecret returns a self-describing hash of the secret using the requested algorithm
+func HashClientSecret(secret string, algorithm ClientSecretHashAlgorithm) (string, error) {
+	switch algorithm {
+	case ClientSecretHashAlgorithmArgon2id, ClientSecretHashAlgorithmUnknown:
+		salt := make([]byte, argon2idSaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(secret), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLen)
+
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, argon2idMemory, argon2idTime, argon2idThreads, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+
+	case ClientSecretHashAlgorithmBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return "", err
+		}
+
+		return string(hash), nil
+	}
+
+	return "", fmt.Errorf("Client secret hash algorithm %v is not supported", algorithm)
+}
+
+// VerifyClientSecretHash returns true if the secret matches the hash created by HashClientSecret
+func VerifyClientSecretHash(hash, secret string) (bool, error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		parts := strings.Split(hash, "$")
+		if len(parts) != 6 {
+			return false, errors.New("argon2id hash has an invalid format")
+		}
+
+		var version int
+		if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+			return false, fmt.Errorf("argon2id hash has an invalid version: %w", err)
+		}
+		if version != argon2.Version {
+			return false, fmt.Errorf("argon2id hash version %v is not supported", version)
+		}
+
+		var memory, time uint32
+		var threads uint8
+		if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+			return false, fmt.Errorf("argon2id hash has invalid parameters: %w", err)
+		}
+
+		salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+		if err != nil {
+			return false, fmt.Errorf("argon2id hash has an invalid salt: %w", err)
+		}
+		key, err := base64.RawStdEncoding.DecodeString(parts[5])
+		if err != nil {
+			return false, fmt.Errorf("argon2id hash has an invalid key: %w", err)
+		}
+
+		if memory == 0 || memory > argon2idMaxMemory || time == 0 || time > argon2idMaxTime || threads < 1 {
+			return false, fmt.Errorf("argon2id hash parameters m=%v,t=%v,p=%v are out of bounds", memory, time, threads)
+		}
+		if len(salt) == 0 {
+			return false, errors.New("argon2id hash has an empty salt")
+		}
+		if len(key) == 0 {
+			return false, errors.New("argon2id hash has an empty key")
+		}
+
+		otherKey := argon2.IDKey([]byte(secret), salt, time, memory, threads, uint32(len(key)))
+
+		return subtle.ConstantTimeCompare(key, otherKey) == 1, nil
+
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(secret))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	return false, errors.New("Client secret hash algorithm is not supported")
+}
+
+// IsExpired returns true if the secret has an expiry time that lies before or at the parameterized time
+func (cs *HashedClientSecret) IsExpired(at time.Time) bool {
+	return cs.ExpiresAt != nil && !cs.ExpiresAt.After(at)
+}
+
+// AddSecret hashes and stores a secret, optionally expiring at the parameterized time
+func (c *Client) AddSecret(secret string, algorithm ClientSecretHashAlgorithm, createdAt time.Time, expiresAt *time.Time) error {
+	hash, err := HashClientSecret(secret, algorithm)
+	if err != nil {
+		return err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+
+	c.Secrets = append(c.Secrets, &HashedClientSecret{
+		ID:        hex.EncodeToString(id),
+		Hash:      hash,
+		CreatedAt: &createdAt,
+		ExpiresAt: expiresAt,
+	})
+
+	return nil
+}
+
+// RotateSecret stores a new secret and lets the existing secrets expire after the grace period
+func (c *Client) RotateSecret(algorithm ClientSecretHashAlgorithm, now time.Time, gracePeriod time.Duration) (string, error) {
+	secret, err := GenerateClientSecret()
+	if err != nil {
+		return "", err
+	}
+
+	expiresAt := now.Add(gracePeriod)
+	for _, s := range c.Secrets {
+		if s.ExpiresAt == nil || s.ExpiresAt.After(expiresAt) {
+			s.ExpiresAt = &expiresAt
+		}
+	}
+
+	// hash the legacy plain text secret so it expires after the grace period like any other secret
+	if c.ClientSecret != "" {
+		if err = c.AddSecret(c.ClientSecret, algorithm, now, &expiresAt); err != nil {
+			return "", err
+		}
+		c.ClientSecret = ""
+	}
+
+	if err = c.AddSecret(secret, algorithm, now, nil); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// RemoveExpiredSecrets removes all secrets that are expired at the parameterized time
+func (c *Client) RemoveExpiredSecrets(at time.Time) {
+	remainingSecrets := []*HashedClientSecret{}
+	for _, s := range c.Secrets {
+		if s != nil && !s.IsExpired(at) {
+			remainingSecrets = append(remainingSecrets, s)
+		}
+	}
+
+	c.Secrets = remainingSecrets
+}
+
+// VerifySecret returns true if the client is active and the presented secret matches any of the non-expired secrets
+func (c *Client) VerifySecret(secret string, at time.Time) bool {
+	if !c.Active || secret == "" {
+		return false
+	}
+
+	for _, s := range c.Secrets {
+		if s == nil || s.IsExpired(at) {
+			continue
+		}
+		if isMatch, err := VerifyClientSecretHash(s.Hash, secret); err == nil && isMatch {
+			return true
+		}
+	}
+
+	// fall back to the legacy plain text secret for clients registered before secrets were hashed
+	if c.ClientSecret != "" {
+		return subtle.ConstantTimeCompare([]byte(c.ClientSecret), []byte(secret)) == 1
+	}
+
+	return false
+}
+
+// HasScope returns true if one of the client's scopes covers the parameterized scope
+func (c *Client) HasScope(scope ClientScope) bool {
+	resource := strings.Split(string(scope), ":")[0]
+	for _, s := range c.Scopes {
+		if s == scope || s == ClientScopeAll || s == ClientScope(resource+":*") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddScope adds a scope if it's not present
+func (c *Client) AddScope(scope ClientScope) {
+	for _, s := range c.Scopes {
+		if s == scope {
+			return
+		}
+	}
+	c.Scopes = append(c.Scopes, scope)
+}
+
+// RemoveScope removes a scope if it's present
+func (c *Client) RemoveScope(scope ClientScope) {
+	remainingScopes := []ClientScope{}
+	for _, s := range c.Scopes {
+		if s != scope {
+			remainingScopes = append(remainingScopes, s)
+		}
+	}
+
+	c.Scopes = remainingScopes
 }
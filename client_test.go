@@ -0,0 +1,271 @@
+package contracts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateClientSecret(t *testing.T) {
+	t.Run("ReturnsDifferentSecretForEachCall", func(t *testing.T) {
+
+		// act
+		secret1, err1 := GenerateClientSecret()
+		secret2, err2 := GenerateClientSecret()
+
+		assert.Nil(t, err1)
+		assert.Nil(t, err2)
+		assert.Equal(t, 43, len(secret1))
+		assert.NotEqual(t, secret1, secret2)
+	})
+}
+
+func TestHashClientSecret(t *testing.T) {
+	t.Run("ReturnsArgon2idHashThatVerifiesWithSameSecret", func(t *testing.T) {
+
+		// act
+		hash, err := HashClientSecret("my-secret", ClientSecretHashAlgorithmArgon2id)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Contains(t, hash, "$argon2id$v=19$")
+		isMatch, err := VerifyClientSecretHash(hash, "my-secret")
+		assert.Nil(t, err)
+		assert.True(t, isMatch)
+	})
+
+	t.Run("ReturnsArgon2idHashThatDoesNotVerifyWithOtherSecret", func(t *testing.T) {
+
+		// act
+		hash, err := HashClientSecret("my-secret", ClientSecretHashAlgorithmArgon2id)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		isMatch, err := VerifyClientSecretHash(hash, "other-secret")
+		assert.Nil(t, err)
+		assert.False(t, isMatch)
+	})
+
+	t.Run("ReturnsBcryptHashThatVerifiesWithSameSecret", func(t *testing.T) {
+
+		// act
+		hash, err := HashClientSecret("my-secret", ClientSecretHashAlgorithmBcrypt)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Contains(t, hash, "$2a$")
+		isMatch, err := VerifyClientSecretHash(hash, "my-secret")
+		assert.Nil(t, err)
+		assert.True(t, isMatch)
+
+		isMatch, err = VerifyClientSecretHash(hash, "other-secret")
+		assert.Nil(t, err)
+		assert.False(t, isMatch)
+	})
+
+	t.Run("ReturnsErrorForUnsupportedAlgorithm", func(t *testing.T) {
+
+		// act
+		_, err := HashClientSecret("my-secret", "md5")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestClientVerifySecret(t *testing.T) {
+	t.Run("ReturnsFalseIfClientIsNotActive", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		client := Client{}
+		err := client.AddSecret("my-secret", ClientSecretHashAlgorithmArgon2id, now, nil)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		isValid := client.VerifySecret("my-secret", now)
+
+		assert.False(t, isValid)
+	})
+
+	t.Run("ReturnsFalseIfSecretIsExpired", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		expiresAt := now.Add(time.Hour)
+		client := Client{Active: true}
+		err := client.AddSecret("my-secret", ClientSecretHashAlgorithmArgon2id, now, &expiresAt)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		isValid := client.VerifySecret("my-secret", now.Add(2*time.Hour))
+
+		assert.False(t, isValid)
+	})
+
+	t.Run("ReturnsTrueForLegacyPlainTextSecret", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		client := Client{Active: true, ClientSecret: "my-secret"}
+
+		// act
+		isValid := client.VerifySecret("my-secret", now)
+
+		assert.True(t, isValid)
+	})
+}
+
+func TestClientRotateSecret(t *testing.T) {
+	t.Run("KeepsOldSecretValidDuringGracePeriod", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		client := Client{Active: true}
+		err := client.AddSecret("old-secret", ClientSecretHashAlgorithmArgon2id, now, nil)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		newSecret, err := client.RotateSecret(ClientSecretHashAlgorithmArgon2id, now, time.Hour)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, 2, len(client.Secrets))
+		assert.True(t, client.VerifySecret("old-secret", now.Add(30*time.Minute)))
+		assert.True(t, client.VerifySecret(newSecret, now.Add(30*time.Minute)))
+		assert.False(t, client.VerifySecret("old-secret", now.Add(2*time.Hour)))
+		assert.True(t, client.VerifySecret(newSecret, now.Add(2*time.Hour)))
+	})
+
+	t.Run("HashesLegacyPlainTextSecret", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		client := Client{Active: true, ClientSecret: "old-secret"}
+
+		// act
+		_, err := client.RotateSecret(ClientSecretHashAlgorithmArgon2id, now, time.Hour)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, "", client.ClientSecret)
+		assert.Equal(t, 2, len(client.Secrets))
+		assert.True(t, client.VerifySecret("old-secret", now.Add(30*time.Minute)))
+		assert.False(t, client.VerifySecret("old-secret", now.Add(2*time.Hour)))
+	})
+}
+
+func TestClientRemoveExpiredSecrets(t *testing.T) {
+	t.Run("RemovesOnlyExpiredSecrets", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		expiresAt := now.Add(-time.Minute)
+		client := Client{
+			Secrets: []*HashedClientSecret{
+				{ID: "1", ExpiresAt: &expiresAt},
+				{ID: "2"},
+			},
+		}
+
+		// act
+		client.RemoveExpiredSecrets(now)
+
+		if !assert.Equal(t, 1, len(client.Secrets)) {
+			return
+		}
+		assert.Equal(t, "2", client.Secrets[0].ID)
+	})
+}
+
+func TestClientHasScope(t *testing.T) {
+	t.Run("ReturnsFalseIfClientHasNoScopes", func(t *testing.T) {
+
+		client := Client{}
+
+		// act
+		hasScope := client.HasScope(ClientScopeReleasesWrite)
+
+		assert.False(t, hasScope)
+	})
+
+	t.Run("ReturnsTrueIfClientHasAllScope", func(t *testing.T) {
+
+		client := Client{Scopes: []ClientScope{ClientScopeAll}}
+
+		// act
+		hasScope := client.HasScope(ClientScopeReleasesWrite)
+
+		assert.True(t, hasScope)
+	})
+
+	t.Run("ReturnsTrueIfClientHasExactScope", func(t *testing.T) {
+
+		client := Client{Scopes: []ClientScope{ClientScopeBuildsRead, ClientScopeReleasesWrite}}
+
+		// act
+		hasScope := client.HasScope(ClientScopeReleasesWrite)
+
+		assert.True(t, hasScope)
+	})
+
+	t.Run("ReturnsFalseIfClientDoesNotHaveScope", func(t *testing.T) {
+
+		client := Client{Scopes: []ClientScope{ClientScopeBuildsRead}}
+
+		// act
+		hasScope := client.HasScope(ClientScopeReleasesWrite)
+
+		assert.False(t, hasScope)
+	})
+
+	t.Run("ReturnsTrueIfClientHasResourceWildcardScope", func(t *testing.T) {
+
+		client := Client{Scopes: []ClientScope{"releases:*"}}
+
+		// act
+		hasScope := client.HasScope(ClientScopeReleasesWrite)
+
+		assert.True(t, hasScope)
+	})
+}
+
+func TestClientRemoveScope(t *testing.T) {
+	t.Run("DeniesEverythingAfterRemovingLastScope", func(t *testing.T) {
+
+		client := Client{Scopes: []ClientScope{ClientScopeBuildsRead}}
+
+		// act
+		client.RemoveScope(ClientScopeBuildsRead)
+
+		assert.Equal(t, 0, len(client.Scopes))
+		assert.False(t, client.HasScope(ClientScopeBuildsRead))
+		assert.False(t, client.HasScope(ClientScopeBuildsWrite))
+	})
+}
+
+func TestVerifyClientSecretHash(t *testing.T) {
+	t.Run("ReturnsErrorForMalformedArgon2idHashes", func(t *testing.T) {
+
+		hashes := []string{
+			"$argon2id$v=19$m=65536,t=1,p=4$c2FsdHNhbHQ$",
+			"$argon2id$v=19$m=65536,t=1,p=0$c2FsdHNhbHQ$a2V5a2V5a2V5",
+			"$argon2id$v=19$m=0,t=1,p=4$c2FsdHNhbHQ$a2V5a2V5a2V5",
+			"$argon2id$v=19$m=65536,t=0,p=4$c2FsdHNhbHQ$a2V5a2V5a2V5",
+			"$argon2id$v=19$m=4294967295,t=1,p=4$c2FsdHNhbHQ$a2V5a2V5a2V5",
+		}
+
+		for _, hash := range hashes {
+			// act
+			isMatch, err := VerifyClientSecretHash(hash, "secret")
+
+			assert.NotNil(t, err, hash)
+			assert.False(t, isMatch)
+		}
+	})
+}
@@ -10,7 +10,7 @@ import (
 	manifest "github.com/estafette/estafette-ci-manifest"
 )
 
-// ContainerRepositoryCredentialConfig is used to authenticate for (private) container repositories (will be replaced by CredentialConfig eventually)
+// ContainerRepositoryCredentialConfig is used to authenticate for (private) container repositories (replaced by CredentialConfig of type container-registry, see UnmarshalBuilderConfigJSON and UnmarshalBuilderConfigYAML)
 type ContainerRepositoryCredentialConfig struct {
 	Repository string `yaml:"repository"`
 	Username   string `yaml:"username"`
@@ -28,6 +28,8 @@ const (
 
 // BuilderConfig parameterizes a build/release job
 type BuilderConfig struct {
+	APIVersion string `yaml:"apiVersion,omitempty" json:"apiVersion,omitempty"`
+
 	JobType JobType        `yaml:"jobType,omitempty" json:"jobType,omitempty"`
 	Build   *Build         `yaml:"build,omitempty" json:"build,omitempty"`
 	Release *Release       `yaml:"release,omitempty" json:"release,omitempty"`
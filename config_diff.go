@@ -0,0 +1,358 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type BuilderConfigChangeType string
+
+const (
+	BuilderConfigChangeTypeUnknown BuilderConfigChangeType = ""
+	BuilderConfigChangeTypeAdded   BuilderConfigChangeType = "added"
+	BuilderConfigChangeTypeRemoved BuilderConfigChangeType = "removed"
+	BuilderConfigChangeTypeChanged BuilderConfigChangeType = "changed"
+)
+
+const redactedCredentialValue = "***"
+
+// BuilderConfigChange is a single difference between two builder configs
+type BuilderConfigChange struct {
+	Path     string                  `json:"path"`
+	Type     BuilderConfigChangeType `json:"type"`
+	OldValue interface{}             `json:"oldValue,omitempty"`
+	NewValue interface{}             `json:"newValue,omitempty"`
+}
+
+// BuilderConfigDiff lists the differences between two builder configs
+type BuilderConfigDiff struct {
+	Changes []BuilderConfigChange `json:"changes"`
+}
+
+// DiffBuilderConfigs returns the differences between two builder configs with credential values redacted
+func DiffBuilderConfigs(old, new *BuilderConfig) BuilderConfigDiff {
+	if old == nil {
+		old = &BuilderConfig{}
+	}
+	if new == nil {
+		new = &BuilderConfig{}
+	}
+
+	diff := BuilderConfigDiff{
+		Changes: []BuilderConfigChange{},
+	}
+
+	oldSections := getBuilderConfigDiffSections(old)
+	newSections := getBuilderConfigDiffSections(new)
+	for _, section := range builderConfigDiffSections {
+		diff.Changes = appendDiffChanges(diff.Changes, section, oldSections[section], newSections[section])
+	}
+
+	return diff
+}
+
+// HasChanges returns true if the builder configs differ
+func (d BuilderConfigDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// String returns the differences one per line
+func (d BuilderConfigDiff) String() string {
+	var sb strings.Builder
+	for _, c := range d.Changes {
+		switch c.Type {
+		case BuilderConfigChangeTypeAdded:
+			sb.WriteString(fmt.Sprintf("+ %v: %v\n", c.Path, formatDiffValue(c.NewValue)))
+		case BuilderConfigChangeTypeRemoved:
+			sb.WriteString(fmt.Sprintf("- %v: %v\n", c.Path, formatDiffValue(c.OldValue)))
+		default:
+			sb.WriteString(fmt.Sprintf("~ %v: %v -> %v\n", c.Path, formatDiffValue(c.OldValue), formatDiffValue(c.NewValue)))
+		}
+	}
+
+	return sb.String()
+}
+
+var builderConfigDiffSections = []string{"manifest", "stages", "credentials", "trustedImages", "dockerConfig", "version", "triggerEvents"}
+
+func getBuilderConfigDiffSections(bc *BuilderConfig) map[string]interface{} {
+	sections := map[string]interface{}{
+		"stages":        getDiffValue(reflect.ValueOf(bc.Stages)),
+		"trustedImages": getDiffValue(reflect.ValueOf(bc.TrustedImages)),
+		"dockerConfig":  getDiffValue(reflect.ValueOf(bc.DockerConfig)),
+		"version":       getDiffValue(reflect.ValueOf(bc.Version)),
+		"triggerEvents": getDiffValue(reflect.ValueOf(bc.Events)),
+	}
+
+	// the manifest's stages are left out, since the stages of the job are compared separately
+	if bc.Manifest != nil {
+		mft := *bc.Manifest
+		mft.Stages = nil
+		sections["manifest"] = getDiffValue(reflect.ValueOf(mft))
+	}
+
+	// credentials are keyed by type and name, since names are only unique per type
+	if len(bc.Credentials) > 0 {
+		credentials := map[string]interface{}{}
+		for _, c := range bc.Credentials {
+			if c == nil {
+				continue
+			}
+			credentials[fmt.Sprintf("%v/%v", c.Type, c.Name)] = getDiffValue(reflect.ValueOf(c))
+		}
+		sections["credentials"] = credentials
+	}
+
+	return sections
+}
+
+// appendDiffChanges compares values normalized by getDiffValue and appends a change for every leaf that differs
+func appendDiffChanges(changes []BuilderConfigChange, path string, old, new interface{}) []BuilderConfigChange {
+	if reflect.DeepEqual(old, new) {
+		return changes
+	}
+	if old == nil {
+		return append(changes, BuilderConfigChange{Path: path, Type: BuilderConfigChangeTypeAdded, NewValue: redactDiffValue(path, new)})
+	}
+	if new == nil {
+		return append(changes, BuilderConfigChange{Path: path, Type: BuilderConfigChangeTypeRemoved, OldValue: redactDiffValue(path, old)})
+	}
+
+	oldMap, oldIsMap := old.(map[string]interface{})
+	newMap, newIsMap := new.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := []string{}
+		for k := range oldMap {
+			keys = append(keys, k)
+		}
+		for k := range newMap {
+			if _, ok := oldMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			changes = appendDiffChanges(changes, getDiffChildPath(path, k, path == "credentials"), oldMap[k], newMap[k])
+		}
+		return changes
+	}
+
+	oldSlice, oldIsSlice := old.([]interface{})
+	newSlice, newIsSlice := new.([]interface{})
+	if oldIsSlice && newIsSlice {
+		oldKeyed, oldKeys := getKeyedDiffSlice(oldSlice)
+		newKeyed, newKeys := getKeyedDiffSlice(newSlice)
+		if oldKeyed != nil && newKeyed != nil {
+			// compare items with a name or path by that key, so inserting a stage doesn't report every later stage as changed
+			keys := append([]string{}, oldKeys...)
+			for _, k := range newKeys {
+				if _, ok := oldKeyed[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			for _, k := range keys {
+				changes = appendDiffChanges(changes, getDiffChildPath(path, k, true), oldKeyed[k], newKeyed[k])
+			}
+			return changes
+		}
+
+		for i := 0; i < len(oldSlice) || i < len(newSlice); i++ {
+			var oldItem, newItem interface{}
+			if i < len(oldSlice) {
+				oldItem = oldSlice[i]
+			}
+			if i < len(newSlice) {
+				newItem = newSlice[i]
+			}
+			changes = appendDiffChanges(changes, getDiffChildPath(path, fmt.Sprint(i), true), oldItem, newItem)
+		}
+		return changes
+	}
+
+	return append(changes, BuilderConfigChange{Path: path, Type: BuilderConfigChangeTypeChanged, OldValue: redactDiffValue(path, old), NewValue: redactDiffValue(path, new)})
+}
+
+// getKeyedDiffSlice returns the items by their name or path, or nil if not every item has a unique one
+func getKeyedDiffSlice(items []interface{}) (map[string]interface{}, []string) {
+	if len(items) == 0 {
+		return map[string]interface{}{}, []string{}
+	}
+
+	keyed := map[string]interface{}{}
+	keys := []string{}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		var key string
+		for _, field := range []string{"Name", "name", "path"} {
+			if value, ok := m[field].(string); ok && value != "" {
+				key = value
+				break
+			}
+		}
+		if _, exists := keyed[key]; key == "" || exists {
+			return nil, nil
+		}
+		keyed[key] = item
+		keys = append(keys, key)
+	}
+
+	return keyed, keys
+}
+
+func getDiffChildPath(path, key string, isItem bool) string {
+	if isItem {
+		return fmt.Sprintf("%v[%v]", path, key)
+	}
+	return fmt.Sprintf("%v.%v", path, key)
+}
+
+// redactDiffValue hides the secret values of credential properties
+func redactDiffValue(path string, value interface{}) interface{} {
+	if !strings.HasPrefix(path, "credentials") {
+		return value
+	}
+	if strings.Contains(path, ".additionalProperties") {
+		return redactCredentialPropertyValue(value)
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	redacted := map[string]interface{}{}
+	for k, v := range m {
+		if k == "additionalProperties" {
+			redacted[k] = redactCredentialPropertyValue(v)
+		} else {
+			redacted[k] = redactDiffValue(path, v)
+		}
+	}
+
+	return redacted
+}
+
+func redactCredentialPropertyValue(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return redactedCredentialValue
+	}
+	redacted := map[string]interface{}{}
+	for k, v := range m {
+		redacted[k] = redactCredentialPropertyValue(v)
+	}
+
+	return redacted
+}
+
+// getDiffValue returns a value as nested maps, slices and scalars, using json names for struct fields
+func getDiffValue(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return getDiffValue(v.Elem())
+
+	case reflect.Struct:
+		result := map[string]interface{}{}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			name, omitEmpty := getDiffFieldName(field)
+			if name == "-" {
+				continue
+			}
+			if omitEmpty && v.Field(i).IsZero() {
+				continue
+			}
+			if value := getDiffValue(v.Field(i)); value != nil {
+				result[name] = value
+			}
+		}
+		if len(result) == 0 {
+			return nil
+		}
+		return result
+
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		result := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			result[fmt.Sprint(iter.Key().Interface())] = getDiffValue(iter.Value())
+		}
+		return result
+
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil
+		}
+		result := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			result = append(result, getDiffValue(v.Index(i)))
+		}
+		return result
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+
+	case reflect.Bool:
+		return v.Bool()
+
+	case reflect.String:
+		return v.String()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+func getDiffFieldName(field reflect.StructField) (name string, omitEmpty bool) {
+	name = field.Name
+
+	tag := field.Tag.Get("json")
+	if tag == "" {
+		return name, false
+	}
+	parts := strings.Split(tag, ",")
+	if parts[0] != "" {
+		name = parts[0]
+	}
+	for _, p := range parts[1:] {
+		if p == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty
+}
+
+func formatDiffValue(value interface{}) string {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(bytes)
+}
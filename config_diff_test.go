@@ -0,0 +1,119 @@
+package contracts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffBuilderConfigs(t *testing.T) {
+	t.Run("ReturnsNoChangesForEqualConfigs", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-builder-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var old, new BuilderConfig
+		if !assert.Nil(t, json.Unmarshal(bytes, &old)) || !assert.Nil(t, json.Unmarshal(bytes, &new)) {
+			return
+		}
+		for _, c := range []*BuilderConfig{&old, &new} {
+			c.Stages = []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.17-alpine", Commands: []string{"go build"}},
+				{Name: "push", ContainerImage: "extensions/docker:dev", CustomProperties: map[string]interface{}{"action": "push", "repositories": []interface{}{"estafette"}}},
+			}
+		}
+
+		// act
+		diff := DiffBuilderConfigs(&old, &new)
+
+		assert.False(t, diff.HasChanges())
+		assert.Equal(t, "", diff.String())
+	})
+
+	t.Run("ReturnsChangesByStageNameAndTrustedImagePath", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-builder-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var old, new BuilderConfig
+		if !assert.Nil(t, json.Unmarshal(bytes, &old)) || !assert.Nil(t, json.Unmarshal(bytes, &new)) {
+			return
+		}
+		for _, c := range []*BuilderConfig{&old, &new} {
+			c.Stages = []*manifest.EstafetteStage{
+				{Name: "build", ContainerImage: "golang:1.17-alpine", Commands: []string{"go build"}},
+				{Name: "push", ContainerImage: "extensions/docker:dev", CustomProperties: map[string]interface{}{"action": "push", "repositories": []interface{}{"estafette"}}},
+			}
+		}
+		new.Stages[0].ContainerImage = "golang:1.18-alpine"
+		new.Stages[1].CustomProperties["repositories"] = []interface{}{"estafette", "extensions"}
+		new.Stages = append([]*manifest.EstafetteStage{{Name: "lint", ContainerImage: "golangci/golangci-lint:v1.45"}}, new.Stages...)
+		new.TrustedImages = new.TrustedImages[1:]
+		new.TrustedImages[0].RunPrivileged = true
+
+		// act
+		diff := DiffBuilderConfigs(&old, &new)
+
+		assert.Equal(t, `~ stages[build].ContainerImage: "golang:1.17-alpine" -> "golang:1.18-alpine"
++ stages[push].CustomProperties.repositories[1]: "extensions"
++ stages[lint]: {"ContainerImage":"golangci/golangci-lint:v1.45","Name":"lint"}
+- trustedImages[extensions/docker]: {"allowCommands":false,"allowNotifications":false,"injectedCredentialTypes":["container-registry"],"path":"extensions/docker","runDocker":true,"runPrivileged":false}
+~ trustedImages[extensions/gke].runPrivileged: false -> true
+`, diff.String())
+	})
+
+	t.Run("ReturnsCredentialChangesWithRedactedValues", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-builder-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var old, new BuilderConfig
+		if !assert.Nil(t, json.Unmarshal(bytes, &old)) || !assert.Nil(t, json.Unmarshal(bytes, &new)) {
+			return
+		}
+		new.Credentials[0].AdditionalProperties["password"] = "rotated-secret"
+		new.Credentials = append(new.Credentials, &CredentialConfig{Name: "github-api-token", Type: "github-api-token", AdditionalProperties: map[string]interface{}{"token": "secret"}})
+
+		// act
+		diff := DiffBuilderConfigs(&old, &new)
+
+		assert.Equal(t, `~ credentials[container-registry/container-registry-extensions].additionalProperties.password: "***" -> "***"
++ credentials[github-api-token/github-api-token]: {"additionalProperties":{"token":"***"},"name":"github-api-token","type":"github-api-token"}
+`, diff.String())
+		assert.NotContains(t, diff.String(), "secret\"")
+	})
+
+	t.Run("ReturnsVersionEventsAndDockerConfigChanges", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-builder-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var old, new BuilderConfig
+		if !assert.Nil(t, json.Unmarshal(bytes, &old)) || !assert.Nil(t, json.Unmarshal(bytes, &new)) {
+			return
+		}
+		new.Version.Version = "0.1.68"
+		new.DockerConfig = &DockerConfig{MTU: 1460}
+		new.Events = []manifest.EstafetteEvent{{Fired: true}}
+
+		// act
+		diff := DiffBuilderConfigs(&old, &new)
+
+		assert.Equal(t, []BuilderConfigChange{
+			{Path: "dockerConfig", Type: BuilderConfigChangeTypeAdded, NewValue: map[string]interface{}{"mtu": int64(1460)}},
+			{Path: "version.version", Type: BuilderConfigChangeTypeChanged, OldValue: "0.1.67-rc.1", NewValue: "0.1.68"},
+			{Path: "triggerEvents", Type: BuilderConfigChangeTypeAdded, NewValue: []interface{}{map[string]interface{}{"fired": true}}},
+		}, diff.Changes)
+
+		bytes, err = json.Marshal(diff)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"changes":[{"path":"dockerConfig","type":"added","newValue":{"mtu":1460}},{"path":"version.version","type":"changed","oldValue":"0.1.67-rc.1","newValue":"0.1.68"},{"path":"triggerEvents","type":"added","newValue":[{"fired":true}]}]}`, string(bytes))
+	})
+}
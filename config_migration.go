@@ -0,0 +1,148 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// BuilderConfigAPIVersionV1 is the version of payloads without apiVersion, which can have legacy container repository credentials
+	BuilderConfigAPIVersionV1 = "v1"
+	// BuilderConfigAPIVersionV2 has all credentials as CredentialConfig
+	BuilderConfigAPIVersionV2 = "v2"
+
+	// CurrentBuilderConfigAPIVersion is the version BuilderConfig payloads are migrated to
+	CurrentBuilderConfigAPIVersion = BuilderConfigAPIVersionV2
+)
+
+// builderConfigPayload holds a BuilderConfig with the fields of older api versions that have been replaced since
+type builderConfigPayload struct {
+	BuilderConfig `yaml:",inline"`
+
+	ContainerRepositoryCredentials []*ContainerRepositoryCredentialConfig `yaml:"containerRepositoryCredentials,omitempty" json:"containerRepositoryCredentials,omitempty"`
+}
+
+// builderConfigMigration upgrades a payload from one api version to the next
+type builderConfigMigration struct {
+	FromVersion string
+	ToVersion   string
+	Migrate     func(payload *builderConfigPayload) error
+}
+
+// builderConfigMigrations is the chain of migrations, ordered from oldest to newest api version
+var builderConfigMigrations = []builderConfigMigration{
+	{
+		FromVersion: BuilderConfigAPIVersionV1,
+		ToVersion:   BuilderConfigAPIVersionV2,
+		Migrate:     migrateContainerRepositoryCredentials,
+	},
+}
+
+// UnmarshalBuilderConfigJSON returns the BuilderConfig in a json payload of any supported api version, migrated to the current api version
+func UnmarshalBuilderConfigJSON(data []byte) (*BuilderConfig, error) {
+	var payload builderConfigPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("BuilderConfig json can't be unmarshalled: %w", err)
+	}
+
+	return migrateBuilderConfigPayload(&payload)
+}
+
+// UnmarshalBuilderConfigYAML returns the BuilderConfig in a yaml payload of any supported api version, migrated to the current api version
+func UnmarshalBuilderConfigYAML(data []byte) (*BuilderConfig, error) {
+	var payload builderConfigPayload
+	if err := yaml.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("BuilderConfig yaml can't be unmarshalled: %w", err)
+	}
+
+	return migrateBuilderConfigPayload(&payload)
+}
+
+// MigrateBuilderConfig upgrades a BuilderConfig to the current api version
+func MigrateBuilderConfig(config *BuilderConfig) (*BuilderConfig, error) {
+	if config == nil {
+		return nil, fmt.Errorf("BuilderConfig is nil and can't be migrated")
+	}
+
+	return migrateBuilderConfigPayload(&builderConfigPayload{BuilderConfig: *config})
+}
+
+func migrateBuilderConfigPayload(payload *builderConfigPayload) (*BuilderConfig, error) {
+	if payload.APIVersion == "" {
+		payload.APIVersion = BuilderConfigAPIVersionV1
+	}
+
+	for _, m := range builderConfigMigrations {
+		if payload.APIVersion != m.FromVersion {
+			continue
+		}
+		if err := m.Migrate(payload); err != nil {
+			return nil, fmt.Errorf("BuilderConfig can't be migrated from api version %v to %v: %w", m.FromVersion, m.ToVersion, err)
+		}
+		payload.APIVersion = m.ToVersion
+	}
+
+	if payload.APIVersion != CurrentBuilderConfigAPIVersion {
+		return nil, fmt.Errorf("BuilderConfig api version %v is not supported, the current api version is %v", payload.APIVersion, CurrentBuilderConfigAPIVersion)
+	}
+	if len(payload.ContainerRepositoryCredentials) > 0 {
+		return nil, fmt.Errorf("BuilderConfig api version %v can't have containerRepositoryCredentials, use credentials of type container-registry instead", payload.APIVersion)
+	}
+
+	config := payload.BuilderConfig
+
+	return &config, nil
+}
+
+// migrateContainerRepositoryCredentials converts legacy container repository credentials into container-registry credentials
+func migrateContainerRepositoryCredentials(payload *builderConfigPayload) error {
+	names := map[string]bool{}
+	for _, c := range payload.Credentials {
+		if c != nil {
+			names[c.Name] = true
+		}
+	}
+
+	for _, crc := range payload.ContainerRepositoryCredentials {
+		if crc == nil {
+			continue
+		}
+		if crc.Repository == "" {
+			return fmt.Errorf("Container repository credential has no repository")
+		}
+
+		exists := false
+		for _, c := range payload.Credentials {
+			if c != nil && c.Type == "container-registry" && c.AdditionalProperties["repository"] == crc.Repository {
+				exists = true
+				break
+			}
+		}
+		if exists {
+			continue
+		}
+
+		baseName := fmt.Sprintf("container-registry-%v", strings.ReplaceAll(crc.Repository, "/", "-"))
+		name := baseName
+		for i := 2; names[name]; i++ {
+			name = fmt.Sprintf("%v-%v", baseName, i)
+		}
+		names[name] = true
+
+		payload.Credentials = append(payload.Credentials, &CredentialConfig{
+			Name: name,
+			Type: "container-registry",
+			AdditionalProperties: map[string]interface{}{
+				"repository": crc.Repository,
+				"username":   crc.Username,
+				"password":   crc.Password,
+			},
+		})
+	}
+	payload.ContainerRepositoryCredentials = nil
+
+	return nil
+}
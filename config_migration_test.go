@@ -0,0 +1,208 @@
+package contracts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestUnmarshalBuilderConfigYAML(t *testing.T) {
+	t.Run("ReturnsSameConfigAsPlainUnmarshalForExistingFixture", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-api-test.yaml")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var expected BuilderConfig
+		err = yaml.Unmarshal(bytes, &expected)
+		if !assert.Nil(t, err) {
+			return
+		}
+		expected.APIVersion = CurrentBuilderConfigAPIVersion
+
+		// act
+		config, err := UnmarshalBuilderConfigYAML(bytes)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, &expected, config)
+	})
+
+	t.Run("ReturnsLegacyContainerRepositoryCredentialsAsContainerRegistryCredentials", func(t *testing.T) {
+
+		bytes := []byte(`
+containerRepositoryCredentials:
+- repository: extensions
+  username: username
+  password: secret
+- repository: estafette/private
+  username: username
+  password: other-secret
+credentials:
+- name: container-registry-extensions
+  type: container-registry
+  repository: extensions
+  username: username
+  password: secret
+`)
+
+		// act
+		config, err := UnmarshalBuilderConfigYAML(bytes)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(config.Credentials)) {
+			return
+		}
+		assert.Equal(t, BuilderConfigAPIVersionV2, config.APIVersion)
+		assert.Equal(t, "container-registry-extensions", config.Credentials[0].Name)
+		assert.Equal(t, &CredentialConfig{
+			Name: "container-registry-estafette-private",
+			Type: "container-registry",
+			AdditionalProperties: map[string]interface{}{
+				"repository": "estafette/private",
+				"username":   "username",
+				"password":   "other-secret",
+			},
+		}, config.Credentials[1])
+	})
+
+	t.Run("ReturnsLegacyCredentialWithUniqueNameIfNameIsTaken", func(t *testing.T) {
+
+		bytes := []byte(`
+containerRepositoryCredentials:
+- repository: extensions
+  username: username
+  password: secret
+credentials:
+- name: container-registry-extensions
+  type: github-api-token
+  token: other-secret
+`)
+
+		// act
+		config, err := UnmarshalBuilderConfigYAML(bytes)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(config.Credentials)) {
+			return
+		}
+		assert.Equal(t, "container-registry-extensions", config.Credentials[0].Name)
+		assert.Equal(t, "github-api-token", config.Credentials[0].Type)
+		assert.Equal(t, "container-registry-extensions-2", config.Credentials[1].Name)
+		assert.Equal(t, "container-registry", config.Credentials[1].Type)
+	})
+
+	t.Run("ReturnsErrorForContainerRepositoryCredentialsInCurrentAPIVersion", func(t *testing.T) {
+
+		bytes := []byte(`
+apiVersion: v2
+containerRepositoryCredentials:
+- repository: extensions
+  username: username
+  password: secret
+`)
+
+		// act
+		_, err := UnmarshalBuilderConfigYAML(bytes)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForUnsupportedAPIVersion", func(t *testing.T) {
+
+		// act
+		_, err := UnmarshalBuilderConfigYAML([]byte("apiVersion: v99\n"))
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestUnmarshalBuilderConfigJSON(t *testing.T) {
+	t.Run("ReturnsSameConfigAsPlainUnmarshalForExistingFixture", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-builder-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var expected BuilderConfig
+		err = json.Unmarshal(bytes, &expected)
+		if !assert.Nil(t, err) {
+			return
+		}
+		expected.APIVersion = CurrentBuilderConfigAPIVersion
+
+		// act
+		config, err := UnmarshalBuilderConfigJSON(bytes)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, &expected, config)
+	})
+
+	t.Run("ReturnsLegacyContainerRepositoryCredentialsAsContainerRegistryCredentials", func(t *testing.T) {
+
+		bytes := []byte(`{"containerRepositoryCredentials":[{"repository":"extensions","username":"username","password":"secret"}]}`)
+
+		// act
+		config, err := UnmarshalBuilderConfigJSON(bytes)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(config.Credentials)) {
+			return
+		}
+		assert.Equal(t, "container-registry-extensions", config.Credentials[0].Name)
+		assert.Equal(t, "container-registry", config.Credentials[0].Type)
+		assert.Equal(t, "secret", config.Credentials[0].AdditionalProperties["password"])
+	})
+
+	t.Run("ReturnsLegacyContainerRepositoryCredentialsWithCapitalizedKeys", func(t *testing.T) {
+
+		bytes := []byte(`{"containerRepositoryCredentials":[{"Repository":"extensions","Username":"username","Password":"secret"}]}`)
+
+		// act
+		config, err := UnmarshalBuilderConfigJSON(bytes)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(config.Credentials)) {
+			return
+		}
+		assert.Equal(t, "extensions", config.Credentials[0].AdditionalProperties["repository"])
+		assert.Equal(t, "secret", config.Credentials[0].AdditionalProperties["password"])
+	})
+
+	t.Run("ReturnsCurrentConfigUnchangedAfterRoundtrip", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-builder-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		config, err := UnmarshalBuilderConfigJSON(bytes)
+		if !assert.Nil(t, err) {
+			return
+		}
+		currentBytes, err := json.Marshal(config)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		roundtripConfig, err := UnmarshalBuilderConfigJSON(currentBytes)
+
+		assert.Nil(t, err)
+		assert.Equal(t, config, roundtripConfig)
+		assert.Contains(t, string(currentBytes), `"apiVersion":"v2"`)
+	})
+}
+
+func TestMigrateBuilderConfig(t *testing.T) {
+	t.Run("SetsCurrentAPIVersionForConfigWithoutAPIVersion", func(t *testing.T) {
+
+		// act
+		config, err := MigrateBuilderConfig(&BuilderConfig{JobType: JobTypeBuild})
+
+		assert.Nil(t, err)
+		assert.Equal(t, CurrentBuilderConfigAPIVersion, config.APIVersion)
+		assert.Equal(t, JobTypeBuild, config.JobType)
+	})
+}
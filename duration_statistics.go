@@ -0,0 +1,251 @@
+package contracts
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	durationSketchRelativeAccuracy = 0.01
+	durationSketchMaxBuckets       = 2048
+)
+
+// DurationStatistics contains percentiles, mean, standard deviation and trend over a series of durations
+type DurationStatistics struct {
+	Count             int           `json:"count"`
+	P50               time.Duration `json:"p50"`
+	P90               time.Duration `json:"p90"`
+	P99               time.Duration `json:"p99"`
+	Mean              time.Duration `json:"mean"`
+	StandardDeviation time.Duration `json:"standardDeviation"`
+	// TrendSlope is the change in duration per item in the series
+	TrendSlope time.Duration `json:"trendSlope"`
+}
+
+// DurationExtraInfo contains statistics over the durations and pending durations of the last x builds, releases or bots
+type DurationExtraInfo struct {
+	DurationStatistics        *DurationStatistics `json:"durationStatistics,omitempty"`
+	PendingDurationStatistics *DurationStatistics `json:"pendingDurationStatistics,omitempty"`
+}
+
+// DurationSketch accumulates durations in bounded memory, with percentiles accurate within 1%
+type DurationSketch struct {
+	gamma      float64
+	buckets    map[int]int
+	zeroCount  int
+	count      int
+	mean       float64
+	m2         float64
+	sumX       float64
+	sumXX      float64
+	sumXY      float64
+	sumY       float64
+	minBucket  int
+	hasBuckets bool
+}
+
+// NewDurationSketch returns an empty sketch
+func NewDurationSketch() *DurationSketch {
+	return &DurationSketch{
+		gamma:   (1 + durationSketchRelativeAccuracy) / (1 - durationSketchRelativeAccuracy),
+		buckets: map[int]int{},
+	}
+}
+
+// Add adds the next duration in chronological order
+func (s *DurationSketch) Add(duration time.Duration) {
+	y := float64(duration)
+	x := float64(s.count)
+
+	s.count++
+	delta := y - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (y - s.mean)
+
+	s.sumX += x
+	s.sumXX += x * x
+	s.sumXY += x * y
+	s.sumY += y
+
+	if duration <= 0 {
+		s.zeroCount++
+		return
+	}
+
+	index := int(math.Ceil(math.Log(y) / math.Log(s.gamma)))
+	if s.hasBuckets && index < s.minBucket {
+		index = s.minBucket
+	}
+	s.buckets[index]++
+
+	if len(s.buckets) > durationSketchMaxBuckets {
+		s.collapseLowestBuckets()
+	}
+}
+
+// Count returns the number of durations added
+func (s *DurationSketch) Count() int {
+	return s.count
+}
+
+// Quantile returns the duration at quantile q between 0 and 1
+func (s *DurationSketch) Quantile(q float64) time.Duration {
+	if s.count == 0 {
+		return 0
+	}
+	if q < 0 {
+		q = 0
+	}
+	if q > 1 {
+		q = 1
+	}
+
+	rank := int(q * float64(s.count-1))
+	if rank < s.zeroCount {
+		return 0
+	}
+
+	cumulative := s.zeroCount
+	for _, index := range s.getSortedBucketIndexes() {
+		cumulative += s.buckets[index]
+		if cumulative > rank {
+			return time.Duration(math.Round(2 * math.Pow(s.gamma, float64(index)) / (s.gamma + 1)))
+		}
+	}
+
+	return 0
+}
+
+// Statistics returns the statistics over all durations added
+func (s *DurationSketch) Statistics() DurationStatistics {
+	statistics := DurationStatistics{
+		Count: s.count,
+		P50:   s.Quantile(0.5),
+		P90:   s.Quantile(0.9),
+		P99:   s.Quantile(0.99),
+		Mean:  time.Duration(math.Round(s.mean)),
+	}
+
+	if s.count > 1 {
+		statistics.StandardDeviation = time.Duration(math.Round(math.Sqrt(s.m2 / float64(s.count-1))))
+
+		n := float64(s.count)
+		if denominator := n*s.sumXX - s.sumX*s.sumX; denominator != 0 {
+			statistics.TrendSlope = time.Duration(math.Round((n*s.sumXY - s.sumX*s.sumY) / denominator))
+		}
+	}
+
+	return statistics
+}
+
+// collapseLowestBuckets merges the two lowest buckets to keep memory bounded, losing accuracy for the lowest durations only
+func (s *DurationSketch) collapseLowestBuckets() {
+	indexes := s.getSortedBucketIndexes()
+	s.buckets[indexes[1]] += s.buckets[indexes[0]]
+	delete(s.buckets, indexes[0])
+	s.minBucket = indexes[1]
+	s.hasBuckets = true
+}
+
+func (s *DurationSketch) getSortedBucketIndexes() []int {
+	indexes := make([]int, 0, len(s.buckets))
+	for index := range s.buckets {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	return indexes
+}
+
+// GetBuildDurationExtraInfo returns duration statistics over finished builds in order of insertion
+func GetBuildDurationExtraInfo(builds []*Build) DurationExtraInfo {
+	sortedBuilds := []*Build{}
+	for _, b := range builds {
+		if b != nil {
+			sortedBuilds = append(sortedBuilds, b)
+		}
+	}
+	sort.SliceStable(sortedBuilds, func(i, j int) bool {
+		return sortedBuilds[i].InsertedAt.Before(sortedBuilds[j].InsertedAt)
+	})
+
+	durations := NewDurationSketch()
+	pendingDurations := NewDurationSketch()
+	for _, b := range sortedBuilds {
+		if b.BuildStatus == StatusSucceeded || b.BuildStatus == StatusFailed {
+			durations.Add(b.Duration)
+		}
+		if b.PendingDuration != nil {
+			pendingDurations.Add(*b.PendingDuration)
+		}
+	}
+
+	return newDurationExtraInfo(durations, pendingDurations)
+}
+
+// GetReleaseDurationExtraInfo returns duration statistics over releases in order of insertion
+func GetReleaseDurationExtraInfo(releases []*Release) DurationExtraInfo {
+	sortedReleases := []*Release{}
+	for _, r := range releases {
+		if r != nil {
+			sortedReleases = append(sortedReleases, r)
+		}
+	}
+	sort.SliceStable(sortedReleases, func(i, j int) bool {
+		return compareTimePointers(sortedReleases[i].InsertedAt, sortedReleases[j].InsertedAt) < 0
+	})
+
+	durations := NewDurationSketch()
+	pendingDurations := NewDurationSketch()
+	for _, r := range sortedReleases {
+		if r.Duration != nil {
+			durations.Add(*r.Duration)
+		}
+		if r.PendingDuration != nil {
+			pendingDurations.Add(*r.PendingDuration)
+		}
+	}
+
+	return newDurationExtraInfo(durations, pendingDurations)
+}
+
+// GetBotDurationExtraInfo returns duration statistics over bots in order of insertion
+func GetBotDurationExtraInfo(bots []*Bot) DurationExtraInfo {
+	sortedBots := []*Bot{}
+	for _, b := range bots {
+		if b != nil {
+			sortedBots = append(sortedBots, b)
+		}
+	}
+	sort.SliceStable(sortedBots, func(i, j int) bool {
+		return compareTimePointers(sortedBots[i].InsertedAt, sortedBots[j].InsertedAt) < 0
+	})
+
+	durations := NewDurationSketch()
+	pendingDurations := NewDurationSketch()
+	for _, b := range sortedBots {
+		if b.Duration != nil {
+			durations.Add(*b.Duration)
+		}
+		if b.PendingDuration != nil {
+			pendingDurations.Add(*b.PendingDuration)
+		}
+	}
+
+	return newDurationExtraInfo(durations, pendingDurations)
+}
+
+func newDurationExtraInfo(durations, pendingDurations *DurationSketch) DurationExtraInfo {
+	extraInfo := DurationExtraInfo{}
+	if durations.Count() > 0 {
+		statistics := durations.Statistics()
+		extraInfo.DurationStatistics = &statistics
+	}
+	if pendingDurations.Count() > 0 {
+		statistics := pendingDurations.Statistics()
+		extraInfo.PendingDurationStatistics = &statistics
+	}
+
+	return extraInfo
+}
@@ -0,0 +1,123 @@
+package contracts
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDurationSketch(t *testing.T) {
+	t.Run("ReturnsExactMeanStandardDeviationAndTrend", func(t *testing.T) {
+
+		sketch := NewDurationSketch()
+		for _, d := range []time.Duration{2 * time.Minute, 4 * time.Minute, 4 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute, 7 * time.Minute, 9 * time.Minute} {
+			sketch.Add(d)
+		}
+
+		// act
+		statistics := sketch.Statistics()
+
+		assert.Equal(t, 8, statistics.Count)
+		assert.Equal(t, 5*time.Minute, statistics.Mean)
+		assert.Equal(t, time.Duration(128285396118), statistics.StandardDeviation)
+		assert.Equal(t, time.Duration(48571428571), statistics.TrendSlope)
+	})
+
+	t.Run("ReturnsPercentilesWithinOnePercent", func(t *testing.T) {
+
+		sketch := NewDurationSketch()
+		for i := 1; i <= 1000; i++ {
+			sketch.Add(time.Duration(i) * time.Second)
+		}
+
+		// act
+		statistics := sketch.Statistics()
+
+		assert.InEpsilon(t, float64(500*time.Second), float64(statistics.P50), 0.01)
+		assert.InEpsilon(t, float64(900*time.Second), float64(statistics.P90), 0.01)
+		assert.InEpsilon(t, float64(990*time.Second), float64(statistics.P99), 0.01)
+		assert.Equal(t, time.Second, statistics.TrendSlope)
+	})
+
+	t.Run("KeepsMemoryBoundedForWideRanges", func(t *testing.T) {
+
+		sketch := NewDurationSketch()
+		random := rand.New(rand.NewSource(1))
+		for i := 0; i < 100000; i++ {
+			sketch.Add(time.Duration(random.Int63()))
+		}
+
+		// act
+		statistics := sketch.Statistics()
+
+		assert.Equal(t, 100000, statistics.Count)
+		assert.LessOrEqual(t, len(sketch.buckets), 2048)
+		assert.True(t, statistics.P50 < statistics.P90)
+		assert.True(t, statistics.P90 <= statistics.P99)
+	})
+
+	t.Run("ReturnsZeroStatisticsWhenEmpty", func(t *testing.T) {
+
+		sketch := NewDurationSketch()
+
+		// act
+		statistics := sketch.Statistics()
+
+		assert.Equal(t, DurationStatistics{}, statistics)
+	})
+}
+
+func TestGetBuildDurationExtraInfo(t *testing.T) {
+	t.Run("ReturnsStatisticsForFinishedBuildsInInsertionOrder", func(t *testing.T) {
+
+		pendingDuration := 10 * time.Second
+		builds := []*Build{
+			{BuildStatus: StatusSucceeded, Duration: 3 * time.Minute, PendingDuration: &pendingDuration, InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+			{BuildStatus: StatusFailed, Duration: 2 * time.Minute, PendingDuration: &pendingDuration, InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{BuildStatus: StatusSucceeded, Duration: 1 * time.Minute, InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+			{BuildStatus: StatusRunning, Duration: 0, InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)},
+		}
+
+		// act
+		extraInfo := GetBuildDurationExtraInfo(builds)
+
+		if !assert.NotNil(t, extraInfo.DurationStatistics) || !assert.NotNil(t, extraInfo.PendingDurationStatistics) {
+			return
+		}
+		assert.Equal(t, 3, extraInfo.DurationStatistics.Count)
+		assert.Equal(t, 2*time.Minute, extraInfo.DurationStatistics.Mean)
+		assert.Equal(t, time.Minute, extraInfo.DurationStatistics.TrendSlope)
+		assert.Equal(t, 2, extraInfo.PendingDurationStatistics.Count)
+	})
+}
+
+func TestGetReleaseDurationExtraInfo(t *testing.T) {
+	t.Run("ReturnsNoStatisticsWithoutDurations", func(t *testing.T) {
+
+		releases := []*Release{{Name: "production"}}
+
+		// act
+		extraInfo := GetReleaseDurationExtraInfo(releases)
+
+		assert.Nil(t, extraInfo.DurationStatistics)
+		assert.Nil(t, extraInfo.PendingDurationStatistics)
+	})
+}
+
+func TestGetBotDurationExtraInfo(t *testing.T) {
+	t.Run("CanBeEmbeddedInBotExtraInfo", func(t *testing.T) {
+
+		duration := 30 * time.Second
+		bots := []*Bot{{Name: "stale-issues", Duration: &duration}}
+		bot := Bot{ExtraInfo: &BotExtraInfo{MedianDuration: duration, DurationExtraInfo: GetBotDurationExtraInfo(bots)}}
+
+		// act
+		bytes, err := json.Marshal(bot.ExtraInfo)
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"medianPendingDuration":0,"medianDuration":30000000000,"durationStatistics":{"count":1,"p50":30189329742,"p90":30189329742,"p99":30189329742,"mean":30000000000,"standardDeviation":0,"trendSlope":0}}`, string(bytes))
+	})
+}
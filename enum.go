@@ -17,6 +17,8 @@ const (
 	StatusCanceling Status = "canceling"
 	// StatusCanceled indicates execution was canceled
 	StatusCanceled Status = "canceled"
+	// StatusAwaitingApproval indicates execution waits for sign-off before it can start
+	StatusAwaitingApproval Status = "awaiting-approval"
 
 	// StatusUnknown provides a default but not allowed status for unmarshalling
 	StatusUnknown Status = ""
@@ -77,7 +79,7 @@ func (l LogStatus) ToStatus() Status {
 
 func (s Status) ToLogStatus() LogStatus {
 	switch s {
-	case StatusPending:
+	case StatusPending, StatusAwaitingApproval:
 		return LogStatusPending
 	case StatusRunning:
 		return LogStatusRunning
@@ -163,3 +163,12 @@ func TestStatusEquals(t *testing.T) {
 		assert.False(t, equal)
 	})
 }
+
+func TestStatusToLogStatus(t *testing.T) {
+	t.Run("ReturnsPendingForAwaitingApproval", func(t *testing.T) {
+		// act
+		logStatus := StatusAwaitingApproval.ToLogStatus()
+
+		assert.Equal(t, LogStatusPending, logStatus)
+	})
+}
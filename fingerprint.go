@@ -0,0 +1,285 @@
+package contracts
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+// stageFingerprint holds the inputs of a stage that determine its result
+type stageFingerprint struct {
+	Image            string                 `json:"image,omitempty"`
+	Shell            string                 `json:"shell,omitempty"`
+	WorkingDirectory string                 `json:"workDir,omitempty"`
+	Commands         []string               `json:"commands,omitempty"`
+	RunInForeground  bool                   `json:"runCommandsInForeground,omitempty"`
+	When             string                 `json:"when,omitempty"`
+	EnvVars          map[string]string      `json:"env,omitempty"`
+	CustomProperties map[string]interface{} `json:"customProperties,omitempty"`
+	ParallelStages   []string               `json:"parallelStages,omitempty"`
+	Services         []serviceFingerprint   `json:"services,omitempty"`
+}
+
+type serviceFingerprint struct {
+	Name             string                   `json:"name,omitempty"`
+	Image            string                   `json:"image,omitempty"`
+	Shell            string                   `json:"shell,omitempty"`
+	Commands         []string                 `json:"commands,omitempty"`
+	RunInForeground  bool                     `json:"runCommandsInForeground,omitempty"`
+	MultiStage       *bool                    `json:"multiStage,omitempty"`
+	When             string                   `json:"when,omitempty"`
+	EnvVars          map[string]string        `json:"env,omitempty"`
+	Readiness        *manifest.ReadinessProbe `json:"readiness,omitempty"`
+	ReadinessProbe   *manifest.ReadinessProbe `json:"readinessProbe,omitempty"`
+	CustomProperties map[string]interface{}   `json:"customProperties,omitempty"`
+}
+
+// credentialFingerprint describes a credential without its secret property values
+type credentialFingerprint struct {
+	Name                 string   `json:"name"`
+	Type                 string   `json:"type"`
+	AllowedPipelines     string   `json:"allowedPipelines,omitempty"`
+	AllowedTrustedImages string   `json:"allowedTrustedImages,omitempty"`
+	AllowedBranches      string   `json:"allowedBranches,omitempty"`
+	PropertyKeys         []string `json:"propertyKeys,omitempty"`
+}
+
+// builderConfigFingerprint holds the inputs of a job that determine its result
+type builderConfigFingerprint struct {
+	JobType             JobType                                `json:"jobType,omitempty"`
+	RepoSource          string                                 `json:"repoSource,omitempty"`
+	RepoOwner           string                                 `json:"repoOwner,omitempty"`
+	RepoName            string                                 `json:"repoName,omitempty"`
+	RepoRevision        string                                 `json:"repoRevision,omitempty"`
+	ReleaseName         string                                 `json:"releaseName,omitempty"`
+	ReleaseAction       string                                 `json:"releaseAction,omitempty"`
+	BotName             string                                 `json:"botName,omitempty"`
+	Track               string                                 `json:"track,omitempty"`
+	Builder             *manifest.EstafetteBuilder             `json:"builder,omitempty"`
+	Labels              map[string]string                      `json:"labels,omitempty"`
+	GlobalEnvVars       map[string]string                      `json:"env,omitempty"`
+	ManifestPreferences *manifest.EstafetteManifestPreferences `json:"manifestPreferences,omitempty"`
+	DockerConfig        *DockerConfig                          `json:"dockerConfig,omitempty"`
+	Stages              []string                               `json:"stages,omitempty"`
+	Credentials         string                                 `json:"credentials,omitempty"`
+	TrustedImages       []*TrustedImageConfig                  `json:"trustedImages,omitempty"`
+}
+
+// stageCacheKey holds a stage fingerprint with the context of the job it runs in
+type stageCacheKey struct {
+	Stage           string                   `json:"stage"`
+	JobType         JobType                  `json:"jobType,omitempty"`
+	RepoSource      string                   `json:"repoSource,omitempty"`
+	RepoOwner       string                   `json:"repoOwner,omitempty"`
+	RepoName        string                   `json:"repoName,omitempty"`
+	RepoRevision    string                   `json:"repoRevision,omitempty"`
+	ReleaseName     string                   `json:"releaseName,omitempty"`
+	ReleaseAction   string                   `json:"releaseAction,omitempty"`
+	Track           string                   `json:"track,omitempty"`
+	OperatingSystem manifest.OperatingSystem `json:"os,omitempty"`
+	GlobalEnvVars   map[string]string        `json:"env,omitempty"`
+	Credentials     string                   `json:"credentials,omitempty"`
+}
+
+// GetStageFingerprint returns a hash of the stage definition
+func GetStageFingerprint(stage *manifest.EstafetteStage) (string, error) {
+	if stage == nil {
+		return "", fmt.Errorf("Stage is nil and can't be fingerprinted")
+	}
+
+	fingerprint := stageFingerprint{
+		Image:            stage.ContainerImage,
+		Shell:            stage.Shell,
+		WorkingDirectory: stage.WorkingDirectory,
+		Commands:         stage.Commands,
+		RunInForeground:  stage.RunCommandsInForeground,
+		When:             stage.When,
+		EnvVars:          stage.EnvVars,
+	}
+	if len(stage.CustomProperties) > 0 {
+		fingerprint.CustomProperties = cleanUpStringMap(stage.CustomProperties)
+	}
+
+	for _, ps := range stage.ParallelStages {
+		parallelStageFingerprint, err := GetStageFingerprint(ps)
+		if err != nil {
+			return "", fmt.Errorf("Parallel stage %v of stage %v can't be fingerprinted: %w", ps.Name, stage.Name, err)
+		}
+		fingerprint.ParallelStages = append(fingerprint.ParallelStages, parallelStageFingerprint)
+	}
+	sort.Strings(fingerprint.ParallelStages)
+
+	for _, s := range stage.Services {
+		if s == nil {
+			continue
+		}
+		service := serviceFingerprint{
+			Name:            s.Name,
+			Image:           s.ContainerImage,
+			Shell:           s.Shell,
+			Commands:        s.Commands,
+			RunInForeground: s.RunCommandsInForeground,
+			MultiStage:      s.MultiStage,
+			When:            s.When,
+			EnvVars:         s.EnvVars,
+			Readiness:       s.Readiness,
+			ReadinessProbe:  s.ReadinessProbe,
+		}
+		if len(s.CustomProperties) > 0 {
+			service.CustomProperties = cleanUpStringMap(s.CustomProperties)
+		}
+		fingerprint.Services = append(fingerprint.Services, service)
+	}
+	sort.SliceStable(fingerprint.Services, func(i, j int) bool {
+		return fingerprint.Services[i].Name < fingerprint.Services[j].Name
+	})
+
+	return getCanonicalHash(fingerprint)
+}
+
+// GetCredentialsFingerprint returns a hash of the credentials without their secret values
+func GetCredentialsFingerprint(credentials []*CredentialConfig) (string, error) {
+	fingerprints := []credentialFingerprint{}
+	for _, c := range credentials {
+		if c == nil {
+			continue
+		}
+		keys := make([]string, 0, len(c.AdditionalProperties))
+		for k := range c.AdditionalProperties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		fingerprints = append(fingerprints, credentialFingerprint{
+			Name:                 c.Name,
+			Type:                 c.Type,
+			AllowedPipelines:     c.AllowedPipelines,
+			AllowedTrustedImages: c.AllowedTrustedImages,
+			AllowedBranches:      c.AllowedBranches,
+			PropertyKeys:         keys,
+		})
+	}
+	sort.SliceStable(fingerprints, func(i, j int) bool {
+		if fingerprints[i].Type != fingerprints[j].Type {
+			return fingerprints[i].Type < fingerprints[j].Type
+		}
+		return fingerprints[i].Name < fingerprints[j].Name
+	})
+
+	return getCanonicalHash(fingerprints)
+}
+
+// GetStageCacheKey returns the key under which the result of a stage in the job can be stored and reused by later jobs
+func GetStageCacheKey(stage *manifest.EstafetteStage, config *BuilderConfig) (string, error) {
+	if config == nil {
+		return "", fmt.Errorf("Builder config is nil and can't be used for a stage cache key")
+	}
+
+	stageFingerprint, err := GetStageFingerprint(stage)
+	if err != nil {
+		return "", err
+	}
+	credentialsFingerprint, err := GetCredentialsFingerprint(config.Credentials)
+	if err != nil {
+		return "", err
+	}
+
+	cacheKey := stageCacheKey{
+		Stage:       stageFingerprint,
+		JobType:     config.JobType,
+		Credentials: credentialsFingerprint,
+	}
+	if config.Git != nil {
+		cacheKey.RepoSource = config.Git.RepoSource
+		cacheKey.RepoOwner = config.Git.RepoOwner
+		cacheKey.RepoName = config.Git.RepoName
+		cacheKey.RepoRevision = config.Git.RepoRevision
+	}
+	if config.Release != nil {
+		cacheKey.ReleaseName = config.Release.Name
+		cacheKey.ReleaseAction = config.Release.Action
+	}
+	if config.Track != nil {
+		cacheKey.Track = *config.Track
+	}
+	if config.Manifest != nil {
+		if cacheKey.Track == "" {
+			cacheKey.Track = config.Manifest.Builder.Track
+		}
+		cacheKey.OperatingSystem = config.Manifest.Builder.OperatingSystem
+		cacheKey.GlobalEnvVars = config.Manifest.GlobalEnvVars
+	}
+
+	return getCanonicalHash(cacheKey)
+}
+
+// GetFingerprint returns a hash of the inputs of the job
+func (bc *BuilderConfig) GetFingerprint() (string, error) {
+	fingerprint := builderConfigFingerprint{
+		JobType:             bc.JobType,
+		DockerConfig:        bc.DockerConfig,
+		ManifestPreferences: bc.ManifestPreferences,
+	}
+
+	if bc.Git != nil {
+		fingerprint.RepoSource = bc.Git.RepoSource
+		fingerprint.RepoOwner = bc.Git.RepoOwner
+		fingerprint.RepoName = bc.Git.RepoName
+		fingerprint.RepoRevision = bc.Git.RepoRevision
+	}
+	if bc.Release != nil {
+		fingerprint.ReleaseName = bc.Release.Name
+		fingerprint.ReleaseAction = bc.Release.Action
+	}
+	if bc.Bot != nil {
+		fingerprint.BotName = bc.Bot.Name
+	}
+	if bc.Track != nil {
+		fingerprint.Track = *bc.Track
+	}
+	if bc.Manifest != nil {
+		fingerprint.Builder = &bc.Manifest.Builder
+		fingerprint.Labels = bc.Manifest.Labels
+		fingerprint.GlobalEnvVars = bc.Manifest.GlobalEnvVars
+	}
+
+	for _, s := range bc.Stages {
+		stageFingerprint, err := GetStageFingerprint(s)
+		if err != nil {
+			return "", err
+		}
+		fingerprint.Stages = append(fingerprint.Stages, stageFingerprint)
+	}
+
+	credentialsFingerprint, err := GetCredentialsFingerprint(bc.Credentials)
+	if err != nil {
+		return "", err
+	}
+	fingerprint.Credentials = credentialsFingerprint
+
+	for _, ti := range bc.TrustedImages {
+		if ti != nil {
+			fingerprint.TrustedImages = append(fingerprint.TrustedImages, ti)
+		}
+	}
+	sort.SliceStable(fingerprint.TrustedImages, func(i, j int) bool {
+		return fingerprint.TrustedImages[i].ImagePath < fingerprint.TrustedImages[j].ImagePath
+	})
+
+	return getCanonicalHash(fingerprint)
+}
+
+// getCanonicalHash returns the sha256 of the json representation, which has map keys sorted
+func getCanonicalHash(value interface{}) (string, error) {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("Value can't be marshalled for hashing: %w", err)
+	}
+	hash := sha256.Sum256(bytes)
+
+	return hex.EncodeToString(hash[:]), nil
+}
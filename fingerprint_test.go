@@ -0,0 +1,254 @@
+package contracts
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestGetStageFingerprint(t *testing.T) {
+	t.Run("ReturnsSameFingerprintRegardlessOfMapAndServiceOrder", func(t *testing.T) {
+
+		var stage, reorderedStage *manifest.EstafetteStage
+		err := yaml.Unmarshal([]byte(`
+name: test
+image: golang:1.17-alpine
+env:
+  CGO_ENABLED: 0
+  GOOS: linux
+commands:
+- go test ./...
+services:
+- name: postgres
+  image: postgres:13
+- name: redis
+  image: redis:6
+custom:
+  a: 1
+  b:
+    c: 2
+`), &stage)
+		if !assert.Nil(t, err) {
+			return
+		}
+		err = yaml.Unmarshal([]byte(`
+name: unit-tests
+custom:
+  b:
+    c: 2
+  a: 1
+services:
+- name: redis
+  image: redis:6
+- name: postgres
+  image: postgres:13
+env:
+  GOOS: linux
+  CGO_ENABLED: 0
+image: golang:1.17-alpine
+commands:
+- go test ./...
+`), &reorderedStage)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		fingerprint, err := GetStageFingerprint(stage)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 64, len(fingerprint))
+		reorderedFingerprint, err := GetStageFingerprint(reorderedStage)
+		assert.Nil(t, err)
+		assert.Equal(t, fingerprint, reorderedFingerprint)
+	})
+
+	t.Run("ReturnsDifferentFingerprintForChangedInputs", func(t *testing.T) {
+
+		stages := []string{
+			"image: golang:1.17-alpine\ncommands:\n- go build\n- go test\n",
+			"image: golang:1.17-alpine\ncommands:\n- go test\n- go build\n",
+			"image: golang:1.18-alpine\ncommands:\n- go build\n- go test\n",
+			"image: golang:1.17-alpine\ncommands:\n- go build\n- go test\nenv:\n  CGO_ENABLED: 0\n",
+			"image: golang:1.17-alpine\ncommands:\n- go build\n- go test\nwhen: status == 'failed'\n",
+		}
+
+		fingerprints := map[string]bool{}
+		for _, s := range stages {
+			var stage *manifest.EstafetteStage
+			err := yaml.Unmarshal([]byte(s), &stage)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			// act
+			fingerprint, err := GetStageFingerprint(stage)
+
+			assert.Nil(t, err)
+			fingerprints[fingerprint] = true
+		}
+
+		assert.Equal(t, len(stages), len(fingerprints))
+	})
+}
+
+func TestGetCredentialsFingerprint(t *testing.T) {
+	t.Run("ReturnsSameFingerprintForDifferentSecretValuesAndOrder", func(t *testing.T) {
+
+		credentials := []*CredentialConfig{
+			{Name: "gke-production", Type: "kubernetes-engine", AdditionalProperties: map[string]interface{}{"serviceAccountKeyfile": "secret-1"}},
+			{Name: "github-api-token", Type: "github-api-token", AdditionalProperties: map[string]interface{}{"token": "secret-2"}},
+		}
+		rotatedCredentials := []*CredentialConfig{
+			{Name: "github-api-token", Type: "github-api-token", AdditionalProperties: map[string]interface{}{"token": "rotated-secret-2"}},
+			{Name: "gke-production", Type: "kubernetes-engine", AdditionalProperties: map[string]interface{}{"serviceAccountKeyfile": "rotated-secret-1"}},
+		}
+
+		// act
+		fingerprint, err := GetCredentialsFingerprint(credentials)
+
+		assert.Nil(t, err)
+		rotatedFingerprint, err := GetCredentialsFingerprint(rotatedCredentials)
+		assert.Nil(t, err)
+		assert.Equal(t, fingerprint, rotatedFingerprint)
+
+		credentials[0].AllowedPipelines = "github.com/estafette/.+"
+		restrictedFingerprint, err := GetCredentialsFingerprint(credentials)
+		assert.Nil(t, err)
+		assert.NotEqual(t, fingerprint, restrictedFingerprint)
+	})
+}
+
+func TestGetStageCacheKey(t *testing.T) {
+	t.Run("ReturnsDifferentKeyForDifferentRevision", func(t *testing.T) {
+
+		stage := &manifest.EstafetteStage{ContainerImage: "golang:1.17-alpine", Commands: []string{"go build"}}
+		config := &BuilderConfig{JobType: JobTypeBuild, Git: &GitConfig{RepoRevision: "5a2d5b2c"}}
+		otherConfig := &BuilderConfig{JobType: JobTypeBuild, Git: &GitConfig{RepoRevision: "9f0e1c3d"}}
+
+		// act
+		key, err := GetStageCacheKey(stage, config)
+
+		assert.Nil(t, err)
+		sameKey, _ := GetStageCacheKey(stage, config)
+		otherKey, _ := GetStageCacheKey(stage, otherConfig)
+		assert.Equal(t, key, sameKey)
+		assert.NotEqual(t, key, otherKey)
+	})
+
+	t.Run("ReturnsDifferentKeyForDifferentRepositoryAtSameRevision", func(t *testing.T) {
+
+		stage := &manifest.EstafetteStage{ContainerImage: "golang:1.17-alpine", Commands: []string{"go build"}}
+		config := &BuilderConfig{JobType: JobTypeBuild, Git: &GitConfig{RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", RepoRevision: "5a2d5b2c"}}
+		forkConfig := &BuilderConfig{JobType: JobTypeBuild, Git: &GitConfig{RepoSource: "github.com", RepoOwner: "someone", RepoName: "estafette-ci-api", RepoRevision: "5a2d5b2c"}}
+
+		// act
+		key, err := GetStageCacheKey(stage, config)
+
+		assert.Nil(t, err)
+		forkKey, err := GetStageCacheKey(stage, forkConfig)
+		assert.Nil(t, err)
+		assert.NotEqual(t, key, forkKey)
+	})
+
+	t.Run("ReturnsDifferentKeyForMatrixCells", func(t *testing.T) {
+
+		stage := &manifest.EstafetteStage{ContainerImage: "golang:${ESTAFETTE_MATRIX_GO_VERSION}-alpine", Commands: []string{"go build"}}
+		config := BuilderConfig{
+			JobType:  JobTypeBuild,
+			Git:      &GitConfig{RepoRevision: "5a2d5b2c"},
+			Manifest: &manifest.EstafetteManifest{},
+		}
+		matrix := BuildMatrix{Axes: []*BuildMatrixAxis{{Name: "go-version", Values: []string{"1.17", "1.18"}}}}
+		configs, err := ExpandBuildMatrix(config, matrix)
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(configs)) {
+			return
+		}
+
+		// act
+		key, err := GetStageCacheKey(stage, configs[0])
+
+		assert.Nil(t, err)
+		otherKey, err := GetStageCacheKey(stage, configs[1])
+		assert.Nil(t, err)
+		assert.NotEqual(t, key, otherKey)
+	})
+
+	t.Run("ReturnsDifferentKeyForReleaseTargetsAndActions", func(t *testing.T) {
+
+		stage := &manifest.EstafetteStage{ContainerImage: "extensions/gke:stable"}
+		configs := []*BuilderConfig{
+			{JobType: JobTypeRelease, Git: &GitConfig{RepoRevision: "5a2d5b2c"}, Release: &Release{Name: "staging"}},
+			{JobType: JobTypeRelease, Git: &GitConfig{RepoRevision: "5a2d5b2c"}, Release: &Release{Name: "production"}},
+			{JobType: JobTypeRelease, Git: &GitConfig{RepoRevision: "5a2d5b2c"}, Release: &Release{Name: "production", Action: "deploy-canary"}},
+			{JobType: JobTypeBuild, Git: &GitConfig{RepoRevision: "5a2d5b2c"}},
+		}
+
+		keys := map[string]bool{}
+		for _, c := range configs {
+			// act
+			key, err := GetStageCacheKey(stage, c)
+
+			assert.Nil(t, err)
+			keys[key] = true
+		}
+
+		assert.Equal(t, len(configs), len(keys))
+	})
+
+	t.Run("ReturnsDifferentKeyForBuilderTrackAndOperatingSystem", func(t *testing.T) {
+
+		stage := &manifest.EstafetteStage{ContainerImage: "golang:1.17-alpine", Commands: []string{"go build"}}
+		configs := []*BuilderConfig{
+			{JobType: JobTypeBuild, Manifest: &manifest.EstafetteManifest{Builder: manifest.EstafetteBuilder{Track: "stable", OperatingSystem: manifest.OperatingSystemLinux}}},
+			{JobType: JobTypeBuild, Manifest: &manifest.EstafetteManifest{Builder: manifest.EstafetteBuilder{Track: "dev", OperatingSystem: manifest.OperatingSystemLinux}}},
+			{JobType: JobTypeBuild, Manifest: &manifest.EstafetteManifest{Builder: manifest.EstafetteBuilder{Track: "stable", OperatingSystem: manifest.OperatingSystemWindows}}},
+		}
+
+		keys := map[string]bool{}
+		for _, c := range configs {
+			// act
+			key, err := GetStageCacheKey(stage, c)
+
+			assert.Nil(t, err)
+			keys[key] = true
+		}
+
+		assert.Equal(t, len(configs), len(keys))
+	})
+}
+
+func TestBuilderConfigGetFingerprint(t *testing.T) {
+	t.Run("ReturnsSameFingerprintForJobsOfSameRevision", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("config-builder-in-builder-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		var config, otherJob BuilderConfig
+		if !assert.Nil(t, json.Unmarshal(bytes, &config)) || !assert.Nil(t, json.Unmarshal(bytes, &otherJob)) {
+			return
+		}
+		jobName := "build-estafette-estafette-ci-api-999999"
+		otherJob.JobName = &jobName
+		otherJob.CIServer = &CIServerConfig{JWT: "another-token"}
+		otherJob.Version = &VersionConfig{Version: "1.0.999"}
+
+		// act
+		fingerprint, err := config.GetFingerprint()
+
+		assert.Nil(t, err)
+		otherFingerprint, err := otherJob.GetFingerprint()
+		assert.Nil(t, err)
+		assert.Equal(t, fingerprint, otherFingerprint)
+
+		otherJob.Git.RepoRevision = "another-revision"
+		otherFingerprint, err = otherJob.GetFingerprint()
+		assert.Nil(t, err)
+		assert.NotEqual(t, fingerprint, otherFingerprint)
+	})
+}
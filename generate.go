@@ -0,0 +1,3 @@
+package contracts
+
+//go:generate go run ./internal/cmd/schemagen -out schemas
@@ -0,0 +1,38 @@
+// Command schemagen writes the JSON Schemas, OpenAPI document and TypeScript declarations for the contract types
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/estafette/estafette-ci-contracts/internal/schemagen"
+)
+
+func main() {
+	outputDir := flag.String("out", "schemas", "directory to write the schemas to")
+	flag.Parse()
+
+	files, err := schemagen.GenerateJSONSchemas()
+	if err != nil {
+		log.Fatalf("Generating schemas failed: %v", err)
+	}
+	files[schemagen.OpenAPIFileName], err = schemagen.GenerateOpenAPIFile()
+	if err != nil {
+		log.Fatalf("Generating OpenAPI document failed: %v", err)
+	}
+	files[schemagen.TypeScriptFileName], err = schemagen.GenerateTypeScript()
+	if err != nil {
+		log.Fatalf("Generating TypeScript declarations failed: %v", err)
+	}
+
+	if err := os.MkdirAll(*outputDir, 0755); err != nil {
+		log.Fatalf("Creating directory %v failed: %v", *outputDir, err)
+	}
+	for name, bytes := range files {
+		if err := os.WriteFile(filepath.Join(*outputDir, name), bytes, 0644); err != nil {
+			log.Fatalf("Writing schema %v failed: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// ContractTypes are the types consumed as json outside of go, for which schemas are generated
+var ContractTypes = []reflect.Type{
+	reflect.TypeOf(contracts.BuilderConfig{}),
+	reflect.TypeOf(contracts.Build{}),
+	reflect.TypeOf(contracts.Release{}),
+	reflect.TypeOf(contracts.Bot{}),
+	reflect.TypeOf(contracts.Pipeline{}),
+	reflect.TypeOf(contracts.BuildLog{}),
+	reflect.TypeOf(contracts.TailLogLine{}),
+	reflect.TypeOf(contracts.EstafetteCiBuilderEvent{}),
+	reflect.TypeOf(contracts.NotificationRecord{}),
+	reflect.TypeOf(contracts.User{}),
+	reflect.TypeOf(contracts.CatalogEntity{}),
+}
+
+// GenerateJSONSchemas returns a JSON Schema document per contract type by file name
+func GenerateJSONSchemas() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for _, t := range ContractTypes {
+		fileName := GetJSONSchemaFileName(t)
+
+		schema, err := GenerateJSONSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		schema.ID = fileName
+
+		bytes, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("Schema for %v can't be marshalled: %w", t, err)
+		}
+		files[fileName] = append(bytes, '\n')
+	}
+
+	return files, nil
+}
+
+// GenerateJSONSchema returns a JSON Schema document for a type, with the named types it uses in $defs
+func GenerateJSONSchema(t reflect.Type) (*Schema, error) {
+	generator := NewGenerator("#/$defs/")
+	ref, err := generator.Add(t)
+	if err != nil {
+		return nil, fmt.Errorf("Schema for %v can't be generated: %w", t, err)
+	}
+
+	return &Schema{
+		SchemaURI: jsonSchemaDialect,
+		Title:     generator.GetName(t),
+		Ref:       ref.Ref,
+		Defs:      generator.Definitions,
+	}, nil
+}
+
+var wordBoundaryRegex = regexp.MustCompile("([a-z0-9])([A-Z])")
+
+// GetJSONSchemaFileName returns the kebab-cased type name with .schema.json extension, like builder-config.schema.json
+func GetJSONSchemaFileName(t reflect.Type) string {
+	return strings.ToLower(wordBoundaryRegex.ReplaceAllString(t.Name(), "$1-$2")) + ".schema.json"
+}
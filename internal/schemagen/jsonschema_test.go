@@ -0,0 +1,138 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	contracts "github.com/estafette/estafette-ci-contracts"
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateJSONSchemas(t *testing.T) {
+	t.Run("ReturnsSchemasEqualToCheckedInSchemas", func(t *testing.T) {
+
+		// act
+		files, err := GenerateJSONSchemas()
+
+		if !assert.Nil(t, err) || !assert.Equal(t, len(ContractTypes), len(files)) {
+			return
+		}
+		checkedInFiles, err := filepath.Glob("../../schemas/*.schema.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, len(files), len(checkedInFiles), "schemas directory has stale files; run go generate")
+		for name, bytes := range files {
+			checkedInBytes, err := ioutil.ReadFile(filepath.Join("../../schemas", name))
+			if !assert.Nil(t, err, "schema %v is missing; run go generate", name) {
+				continue
+			}
+			assert.Equal(t, string(checkedInBytes), string(bytes), "schema %v is out of date; run go generate", name)
+		}
+	})
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	t.Run("ReturnsEnumConstraintForStringTypesWithConstants", func(t *testing.T) {
+
+		// act
+		schema, err := GenerateJSONSchema(reflect.TypeOf(contracts.Build{}))
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, "#/$defs/Build", schema.Ref)
+		assert.Equal(t, []string{"pending", "running", "succeeded", "failed", "canceling", "canceled", "awaiting-approval"}, schema.Defs["Status"].Enum)
+		assert.Equal(t, "string", schema.Defs["Status"].Type)
+	})
+
+	t.Run("ReturnsOmitEmptyFieldsAsOptionalAndOtherFieldsAsRequired", func(t *testing.T) {
+
+		// act
+		schema, err := GenerateJSONSchema(reflect.TypeOf(contracts.BuildLogLine{}))
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		bytes, err := json.Marshal(schema.Defs["BuildLogLine"])
+		assert.Nil(t, err)
+		assert.Equal(t, `{"description":"BuildLogLine has low level log information","type":"object","properties":{"line":{"type":"integer"},"timestamp":{"type":"string","format":"date-time"},"streamType":{"type":"string"},"text":{"type":"string"}},"required":["timestamp","streamType","text"]}`, string(bytes))
+	})
+
+	t.Run("ReturnsNullableSchemaForRequiredPointers", func(t *testing.T) {
+
+		type withPointer struct {
+			Build *contracts.Build `json:"build"`
+		}
+
+		// act
+		schema, err := GenerateJSONSchema(reflect.TypeOf(withPointer{}))
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		property, nullable := schema.Defs["withPointer"].Properties[0].Schema.IsNullable()
+		assert.True(t, nullable)
+		assert.Equal(t, "#/$defs/Build", property.Ref)
+	})
+	t.Run("ReturnsEnumsAllowingMarshalledZeroValues", func(t *testing.T) {
+
+		values := []interface{}{
+			manifest.EstafetteBuilder{},
+			contracts.TailLogLine{},
+			contracts.BuildLogStep{},
+			contracts.ReleaseApproval{},
+		}
+
+		for _, v := range values {
+			// act
+			schema, err := GenerateJSONSchema(reflect.TypeOf(v))
+
+			if !assert.Nil(t, err) {
+				return
+			}
+			bytes, err := json.Marshal(v)
+			if !assert.Nil(t, err) {
+				return
+			}
+			var properties map[string]interface{}
+			if !assert.Nil(t, json.Unmarshal(bytes, &properties)) {
+				return
+			}
+			for _, p := range schema.Defs[strings.TrimPrefix(schema.Ref, "#/$defs/")].Properties {
+				if value, ok := properties[p.Name].(string); ok {
+					assert.True(t, allowsString(schema.Defs, p.Schema, value), "property %v of %v doesn't allow %q", p.Name, reflect.TypeOf(v), value)
+				}
+			}
+		}
+	})
+}
+
+func allowsString(defs map[string]*Schema, schema *Schema, value string) bool {
+	if schema.Ref != "" {
+		return allowsString(defs, defs[strings.TrimPrefix(schema.Ref, "#/$defs/")], value)
+	}
+	if len(schema.AnyOf) > 0 {
+		for _, a := range schema.AnyOf {
+			if allowsString(defs, a, value) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(schema.Enum) > 0 {
+		for _, e := range schema.Enum {
+			if e == value {
+				return true
+			}
+		}
+		return false
+	}
+
+	return schema.Type == "" || schema.Type == "string"
+}
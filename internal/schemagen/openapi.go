@@ -0,0 +1,109 @@
+package schemagen
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	openAPIVersion  = "3.0.3"
+	OpenAPIFileName = "openapi.json"
+)
+
+// OpenAPIDocument is an OpenAPI 3 document with only components, for generating clients for the contract types
+type OpenAPIDocument struct {
+	OpenAPI    string            `json:"openapi"`
+	Info       OpenAPIInfo       `json:"info"`
+	Paths      map[string]string `json:"paths"`
+	Components OpenAPIComponents `json:"components"`
+}
+
+// OpenAPIInfo describes the document
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// OpenAPIComponents has a schema for each contract type and the types they use
+type OpenAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// GenerateOpenAPI returns the OpenAPI document with component schemas for all contract types
+func GenerateOpenAPI() (*OpenAPIDocument, error) {
+	generator := NewGenerator("#/components/schemas/")
+	for _, t := range ContractTypes {
+		if _, err := generator.Add(t); err != nil {
+			return nil, fmt.Errorf("OpenAPI schema for %v can't be generated: %w", t, err)
+		}
+	}
+
+	schemas := map[string]*Schema{}
+	for name, s := range generator.Definitions {
+		schemas[name] = toOpenAPISchema(s)
+	}
+
+	return &OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       "Estafette CI contracts",
+			Description: "Times are strings in RFC 3339 format (date-time); durations are integers in nanoseconds (int64).",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]string{},
+		Components: OpenAPIComponents{
+			Schemas: schemas,
+		},
+	}, nil
+}
+
+// GenerateOpenAPIFile returns the OpenAPI document as indented json
+func GenerateOpenAPIFile() ([]byte, error) {
+	document, err := GenerateOpenAPI()
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("OpenAPI document can't be marshalled: %w", err)
+	}
+
+	return append(bytes, '\n'), nil
+}
+
+// toOpenAPISchema converts a JSON Schema to an OpenAPI 3.0 schema, which marks nullable schemas with a flag instead of a null type
+func toOpenAPISchema(s *Schema) *Schema {
+	if s == nil {
+		return nil
+	}
+
+	if inner, nullable := s.IsNullable(); nullable {
+		converted := toOpenAPISchema(inner)
+		// a reference can't have siblings, so it's wrapped in allOf to mark it nullable
+		if converted.Ref != "" {
+			return &Schema{AllOf: []*Schema{converted}, Nullable: true}
+		}
+		converted.Nullable = true
+		return converted
+	}
+
+	converted := *s
+	converted.Items = toOpenAPISchema(s.Items)
+	converted.AdditionalProperties = toOpenAPISchema(s.AdditionalProperties)
+	if s.Properties != nil {
+		converted.Properties = Properties{}
+		for _, p := range s.Properties {
+			converted.Properties = append(converted.Properties, Property{Name: p.Name, Schema: toOpenAPISchema(p.Schema)})
+		}
+	}
+	if s.AnyOf != nil {
+		converted.AnyOf = []*Schema{}
+		for _, a := range s.AnyOf {
+			converted.AnyOf = append(converted.AnyOf, toOpenAPISchema(a))
+		}
+	}
+
+	return &converted
+}
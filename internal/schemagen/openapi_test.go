@@ -0,0 +1,74 @@
+package schemagen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateOpenAPIFile(t *testing.T) {
+	t.Run("ReturnsDocumentEqualToCheckedInDocument", func(t *testing.T) {
+
+		// act
+		bytes, err := GenerateOpenAPIFile()
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		checkedInBytes, err := ioutil.ReadFile(filepath.Join("../../schemas", OpenAPIFileName))
+		if !assert.Nil(t, err, "OpenAPI document is missing; run go generate") {
+			return
+		}
+		assert.Equal(t, string(checkedInBytes), string(bytes), "OpenAPI document is out of date; run go generate")
+	})
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	t.Run("ReturnsComponentSchemasForContractTypes", func(t *testing.T) {
+
+		// act
+		document, err := GenerateOpenAPI()
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, "3.0.3", document.OpenAPI)
+		for _, ct := range ContractTypes {
+			assert.Contains(t, document.Components.Schemas, ct.Name())
+		}
+		assert.Equal(t, []string{"pending", "running", "succeeded", "failed", "canceling", "canceled", "awaiting-approval"}, document.Components.Schemas["Status"].Enum)
+	})
+
+	t.Run("ReturnsOmitEmptyFieldsAsOptionalWithDocumentedFormats", func(t *testing.T) {
+
+		// act
+		document, err := GenerateOpenAPI()
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		build := document.Components.Schemas["Build"]
+		assert.Contains(t, build.Required, "duration")
+		assert.NotContains(t, build.Required, "pendingDuration")
+		for _, p := range build.Properties {
+			switch p.Name {
+			case "insertedAt":
+				assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, p.Schema)
+			case "duration":
+				assert.Equal(t, &Schema{Type: "integer", Format: "int64", Description: "Duration in nanoseconds"}, p.Schema)
+			}
+		}
+	})
+
+	t.Run("ReturnsNullableReferencesWrappedInAllOf", func(t *testing.T) {
+
+		schema := &Schema{AnyOf: []*Schema{{Ref: "#/components/schemas/Build"}, {Type: "null"}}}
+
+		// act
+		converted := toOpenAPISchema(schema)
+
+		assert.Equal(t, &Schema{AllOf: []*Schema{{Ref: "#/components/schemas/Build"}}, Nullable: true}, converted)
+	})
+}
@@ -0,0 +1,116 @@
+package schemagen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// packageInfo has what reflection can't tell about a package, like the values of its string enums and the doc comments of its types
+type packageInfo struct {
+	Enums map[string][]string
+	Docs  map[string]string
+}
+
+// packageLoader parses the source of packages once and caches the result
+type packageLoader struct {
+	packages map[string]*packageInfo
+}
+
+func newPackageLoader() *packageLoader {
+	return &packageLoader{
+		packages: map[string]*packageInfo{},
+	}
+}
+
+func (l *packageLoader) get(pkgPath string) (*packageInfo, error) {
+	if info, ok := l.packages[pkgPath]; ok {
+		return info, nil
+	}
+
+	pkg, err := build.Default.Import(pkgPath, ".", 0)
+	if err != nil {
+		return nil, fmt.Errorf("Package %v can't be found: %w", pkgPath, err)
+	}
+
+	info := &packageInfo{
+		Enums: map[string][]string{},
+		Docs:  map[string]string{},
+	}
+
+	fset := token.NewFileSet()
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("File %v of package %v can't be parsed: %w", name, pkgPath, err)
+		}
+
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+
+			switch genDecl.Tok {
+			case token.CONST:
+				for _, spec := range genDecl.Specs {
+					addEnumValues(info, spec.(*ast.ValueSpec))
+				}
+
+			case token.TYPE:
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					doc := typeSpec.Doc
+					if doc == nil && len(genDecl.Specs) == 1 {
+						doc = genDecl.Doc
+					}
+					if doc != nil {
+						info.Docs[typeSpec.Name.Name] = strings.Join(strings.Fields(doc.Text()), " ")
+					}
+				}
+			}
+		}
+	}
+
+	l.packages[pkgPath] = info
+
+	return info, nil
+}
+
+// addEnumValues adds the string values of constants declared with an explicit type
+func addEnumValues(info *packageInfo, spec *ast.ValueSpec) {
+	typeIdent, ok := spec.Type.(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	for i, v := range spec.Values {
+		lit, ok := v.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			continue
+		}
+		value, err := strconv.Unquote(lit.Value)
+		if err != nil || containsString(info.Enums[typeIdent.Name], value) {
+			continue
+		}
+		// an empty Unknown constant is a placeholder, while an empty constant like StorageMediumDefault is a valid value
+		if value == "" && i < len(spec.Names) && strings.HasSuffix(spec.Names[i].Name, "Unknown") {
+			continue
+		}
+		info.Enums[typeIdent.Name] = append(info.Enums[typeIdent.Name], value)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,340 @@
+package schemagen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+// Schema is the subset of JSON Schema needed to describe the contract types
+type Schema struct {
+	SchemaURI            string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           Properties         `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Property is a named property of an object schema
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties keeps the properties of an object schema in the order of the struct fields
+type Properties []Property
+
+// MarshalJSON returns the properties as json object in field order
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, property := range p {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteString(":")
+		buf.Write(schema)
+	}
+	buf.WriteString("}")
+
+	return buf.Bytes(), nil
+}
+
+// IsNullable returns true if the schema allows null next to a single other schema, which is how nullable fields are described
+func (s *Schema) IsNullable() (*Schema, bool) {
+	if len(s.AnyOf) != 2 || s.AnyOf[1].Type != "null" {
+		return s, false
+	}
+	return s.AnyOf[0], true
+}
+
+var (
+	timeType                = reflect.TypeOf(time.Time{})
+	durationType            = reflect.TypeOf(time.Duration(0))
+	stringOrStringArrayType = reflect.TypeOf(manifest.StringOrStringArray{})
+	jsonMarshalerType       = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	jsonUnmarshalerType     = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// Generator collects the schemas of named types as definitions, so they're described once and referenced where they're used
+type Generator struct {
+	refPrefix   string
+	packages    *packageLoader
+	names       map[reflect.Type]string
+	types       map[string]reflect.Type
+	Definitions map[string]*Schema
+}
+
+// NewGenerator returns a generator that references definitions by refPrefix followed by their name, like #/$defs/
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{
+		refPrefix:   refPrefix,
+		packages:    newPackageLoader(),
+		names:       map[reflect.Type]string{},
+		types:       map[string]reflect.Type{},
+		Definitions: map[string]*Schema{},
+	}
+}
+
+// Add returns the schema for a type, adding the named types it uses to the definitions
+func (g *Generator) Add(t reflect.Type) (*Schema, error) {
+	return g.getSchema(t)
+}
+
+// GetName returns the definition name of a named type
+func (g *Generator) GetName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if other, ok := g.types[name]; ok && other != t {
+		// prefix the package name for types with the same name in different packages
+		pkgName := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkgName[:1]) + pkgName[1:] + name
+	}
+	g.names[t] = name
+	g.types[name] = t
+
+	return name
+}
+
+func (g *Generator) getSchema(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "Duration in nanoseconds"}, nil
+	case stringOrStringArrayType:
+		return &Schema{AnyOf: []*Schema{{Type: "string"}, {Type: "array", Items: &Schema{Type: "string"}}}}, nil
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return nil, fmt.Errorf("Type %v has custom json marshalling and needs an explicit schema", t)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}, nil
+
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+
+	case reflect.String:
+		return g.getStringSchema(t)
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := g.getSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Map %v has no string keys and can't be described", t)
+		}
+		values, err := g.getSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+
+	case reflect.Interface:
+		return &Schema{}, nil
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.getStructSchema(t)
+		}
+		return g.getDefinitionRef(t, g.getStructSchema)
+	}
+
+	return nil, fmt.Errorf("Type %v of kind %v can't be described", t, t.Kind())
+}
+
+// getStringSchema returns a reference to an enum definition for string types with typed constants, and a plain string schema otherwise
+func (g *Generator) getStringSchema(t reflect.Type) (*Schema, error) {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return &Schema{Type: "string"}, nil
+	}
+
+	info, err := g.packages.get(t.PkgPath())
+	if err != nil {
+		return nil, err
+	}
+	values, ok := info.Enums[t.Name()]
+	if !ok {
+		return &Schema{Type: "string"}, nil
+	}
+
+	return g.getDefinitionRef(t, func(t reflect.Type) (*Schema, error) {
+		return &Schema{Type: "string", Enum: values}, nil
+	})
+}
+
+// isEmptyEnumValueAllowed returns false for enums that don't have the empty string as one of their values
+func (g *Generator) isEmptyEnumValueAllowed(t reflect.Type) (bool, error) {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return true, nil
+	}
+
+	info, err := g.packages.get(t.PkgPath())
+	if err != nil {
+		return false, err
+	}
+	values, isEnum := info.Enums[t.Name()]
+
+	return !isEnum || containsString(values, ""), nil
+}
+
+func (g *Generator) getDefinitionRef(t reflect.Type, getDefinition func(t reflect.Type) (*Schema, error)) (*Schema, error) {
+	name := g.GetName(t)
+	ref := &Schema{Ref: g.refPrefix + name}
+	if _, ok := g.Definitions[name]; ok {
+		return ref, nil
+	}
+
+	// add a placeholder first, so recursive types reference the definition instead of recursing endlessly
+	g.Definitions[name] = &Schema{}
+	definition, err := getDefinition(t)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := g.packages.get(t.PkgPath())
+	if err != nil {
+		return nil, err
+	}
+	if doc, ok := info.Docs[t.Name()]; ok {
+		definition.Description = doc
+	}
+	*g.Definitions[name] = *definition
+
+	return ref, nil
+}
+
+func (g *Generator) getStructSchema(t reflect.Type) (*Schema, error) {
+	schema := &Schema{
+		Type:       "object",
+		Properties: Properties{},
+	}
+	if err := g.addStructProperties(schema, t); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// addStructProperties adds a property per exported field by its json name, flattening embedded structs like encoding/json does
+func (g *Generator) addStructProperties(schema *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name, omitEmpty, skip := getJSONField(field)
+		if skip {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if err := g.addStructProperties(schema, fieldType); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := g.getSchema(field.Type)
+		if err != nil {
+			return fmt.Errorf("Field %v of %v can't be described: %w", field.Name, t, err)
+		}
+
+		if !omitEmpty {
+			schema.Required = append(schema.Required, name)
+
+			// nil pointers, slices and maps are marshalled as null unless omitted
+			switch field.Type.Kind() {
+			case reflect.Ptr, reflect.Slice, reflect.Map:
+				property = &Schema{AnyOf: []*Schema{property, {Type: "null"}}}
+			case reflect.String:
+				isEmptyAllowed, err := g.isEmptyEnumValueAllowed(field.Type)
+				if err != nil {
+					return fmt.Errorf("Field %v of %v can't be described: %w", field.Name, t, err)
+				}
+				// the empty zero value of an enum is marshalled unless omitted
+				if !isEmptyAllowed {
+					property = &Schema{AnyOf: []*Schema{property, {Type: "string", Enum: []string{""}}}}
+				}
+			}
+		}
+
+		schema.Properties = append(schema.Properties, Property{Name: name, Schema: property})
+	}
+
+	return nil
+}
+
+func getJSONField(field reflect.StructField) (name string, omitEmpty, skip bool) {
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		return "", false, false
+	}
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+	for _, p := range parts[1:] {
+		if p == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return parts[0], omitEmpty, false
+}
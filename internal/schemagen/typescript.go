@@ -0,0 +1,139 @@
+package schemagen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const TypeScriptFileName = "estafette-ci-contracts.d.ts"
+
+var typeScriptIdentifierRegex = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// GenerateTypeScript returns a TypeScript declaration file with an interface per struct and a string-literal union per enum used by the contract types
+func GenerateTypeScript() ([]byte, error) {
+	generator := NewGenerator("")
+	for _, t := range ContractTypes {
+		if _, err := generator.Add(t); err != nil {
+			return nil, fmt.Errorf("TypeScript declaration for %v can't be generated: %w", t, err)
+		}
+	}
+
+	names := []string{}
+	for name := range generator.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by schemagen from the estafette-ci-contracts go types; DO NOT EDIT.\n")
+	for _, name := range names {
+		definition := generator.Definitions[name]
+
+		sb.WriteString("\n")
+		writeTypeScriptComment(&sb, "", definition.Description)
+		if definition.Type == "object" && definition.AdditionalProperties == nil {
+			writeTypeScriptInterface(&sb, name, definition)
+		} else {
+			sb.WriteString(fmt.Sprintf("export type %v = %v;\n", name, getTypeScriptType(definition)))
+		}
+	}
+
+	return []byte(sb.String()), nil
+}
+
+func writeTypeScriptInterface(sb *strings.Builder, name string, definition *Schema) {
+	required := map[string]bool{}
+	for _, r := range definition.Required {
+		required[r] = true
+	}
+
+	sb.WriteString(fmt.Sprintf("export interface %v {\n", name))
+	for _, p := range definition.Properties {
+		writeTypeScriptComment(sb, "  ", getTypeScriptPropertyComment(p.Schema))
+
+		propertyName := p.Name
+		if !typeScriptIdentifierRegex.MatchString(propertyName) {
+			propertyName = fmt.Sprintf("%q", propertyName)
+		}
+		optional := "?"
+		if required[p.Name] {
+			optional = ""
+		}
+		sb.WriteString(fmt.Sprintf("  %v%v: %v;\n", propertyName, optional, getTypeScriptType(p.Schema)))
+	}
+	sb.WriteString("}\n")
+}
+
+// getTypeScriptPropertyComment documents the formats TypeScript types can't express, like times and durations
+func getTypeScriptPropertyComment(s *Schema) string {
+	if inner, nullable := s.IsNullable(); nullable {
+		s = inner
+	}
+	if s.Description != "" {
+		return s.Description
+	}
+	if s.Format == "date-time" {
+		return "Time in RFC 3339 format"
+	}
+	return ""
+}
+
+func writeTypeScriptComment(sb *strings.Builder, indent, comment string) {
+	if comment == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%v/** %v */\n", indent, strings.ReplaceAll(comment, "*/", "*\\/")))
+}
+
+func getTypeScriptType(s *Schema) string {
+	if s.Ref != "" {
+		return s.Ref
+	}
+	if inner, nullable := s.IsNullable(); nullable {
+		return getTypeScriptType(inner) + " | null"
+	}
+	if len(s.AnyOf) > 0 {
+		types := []string{}
+		for _, a := range s.AnyOf {
+			types = append(types, getTypeScriptType(a))
+		}
+		return strings.Join(types, " | ")
+	}
+	if len(s.Enum) > 0 {
+		values := []string{}
+		for _, e := range s.Enum {
+			values = append(values, fmt.Sprintf("%q", e))
+		}
+		return strings.Join(values, " | ")
+	}
+
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		itemType := getTypeScriptType(s.Items)
+		if strings.Contains(itemType, " | ") {
+			return fmt.Sprintf("Array<%v>", itemType)
+		}
+		return itemType + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return fmt.Sprintf("{ [key: string]: %v }", getTypeScriptType(s.AdditionalProperties))
+		}
+		var sb strings.Builder
+		sb.WriteString("{ ")
+		for _, p := range s.Properties {
+			sb.WriteString(fmt.Sprintf("%q: %v; ", p.Name, getTypeScriptType(p.Schema)))
+		}
+		sb.WriteString("}")
+		return sb.String()
+	}
+
+	return "unknown"
+}
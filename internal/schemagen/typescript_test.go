@@ -0,0 +1,76 @@
+package schemagen
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateTypeScript(t *testing.T) {
+	t.Run("ReturnsDeclarationsEqualToCheckedInGoldenFile", func(t *testing.T) {
+
+		// act
+		bytes, err := GenerateTypeScript()
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		checkedInBytes, err := ioutil.ReadFile(filepath.Join("../../schemas", TypeScriptFileName))
+		if !assert.Nil(t, err, "TypeScript declarations are missing; run go generate") {
+			return
+		}
+		assert.Equal(t, string(checkedInBytes), string(bytes), "TypeScript declarations are out of date; run go generate")
+	})
+
+	t.Run("ReturnsStringLiteralUnionsForEnums", func(t *testing.T) {
+
+		// act
+		bytes, err := GenerateTypeScript()
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Contains(t, string(bytes), "export type LogStatus = \"UNKNOWN\" | \"SUCCEEDED\" | \"FAILED\" | \"SKIPPED\" | \"CANCELED\" | \"PENDING\" | \"RUNNING\";\n")
+		assert.Contains(t, string(bytes), "export type Status = \"pending\" | \"running\" | \"succeeded\" | \"failed\" | \"canceling\" | \"canceled\" | \"awaiting-approval\";\n")
+	})
+
+	t.Run("ReturnsOptionalPropertiesForOmitEmptyFieldsAndNestedTypes", func(t *testing.T) {
+
+		// act
+		bytes, err := GenerateTypeScript()
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Contains(t, string(bytes), `/** TailLogLine returns a log line for streaming logs to gui during a build */
+export interface TailLogLine {
+  step: string;
+  parentStage?: string;
+  type: LogType | "";
+  depth?: number;
+  runIndex?: number;
+  logLine?: BuildLogLine;
+  image?: BuildLogStepDockerImage;
+  /** Duration in nanoseconds */
+  duration?: number;
+  exitCode?: number;
+  status?: LogStatus;
+  autoInjected?: boolean;
+}
+`)
+	})
+}
+
+func TestGetTypeScriptType(t *testing.T) {
+	t.Run("ReturnsArrayOfUnionForNullableItems", func(t *testing.T) {
+
+		schema := &Schema{Type: "array", Items: &Schema{AnyOf: []*Schema{{Type: "string"}, {Type: "null"}}}}
+
+		// act
+		tsType := getTypeScriptType(schema)
+
+		assert.Equal(t, "Array<string | null>", tsType)
+	})
+}
@@ -0,0 +1,206 @@
+package contracts
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+type JWTAlgorithm string
+
+const (
+	JWTAlgorithmUnknown JWTAlgorithm = ""
+	JWTAlgorithmHS256   JWTAlgorithm = "HS256"
+	JWTAlgorithmRS256   JWTAlgorithm = "RS256"
+)
+
+var (
+	// ErrJWTMalformed is returned when a token can't be decoded
+	ErrJWTMalformed = errors.New("jwt is malformed")
+	// ErrJWTInvalidSignature is returned when a token signature doesn't match the key
+	ErrJWTInvalidSignature = errors.New("jwt signature is invalid")
+	// ErrJWTExpired is returned when a token is used after its expiry time
+	ErrJWTExpired = errors.New("jwt is expired")
+	// ErrJWTMissingExpiry is returned when a token has no expiry time
+	ErrJWTMissingExpiry = errors.New("jwt has no expiry")
+	// ErrJWTUnsupportedKey is returned when a key is not a non-empty hmac secret or rsa key
+	ErrJWTUnsupportedKey = errors.New("jwt key type is not supported")
+)
+
+// JWTClaims are the claims inside the JWT handed to a builder in CIServerConfig
+type JWTClaims struct {
+	JobType          JobType  `json:"jobType,omitempty"`
+	JobID            string   `json:"jobID,omitempty"`
+	RepoPath         string   `json:"repoPath,omitempty"`
+	AllowedEndpoints []string `json:"allowedEndpoints,omitempty"`
+	Issuer           string   `json:"iss,omitempty"`
+	IssuedAt         int64    `json:"iat,omitempty"`
+	ExpiresAt        int64    `json:"exp,omitempty"`
+}
+
+type jwtHeader struct {
+	Algorithm JWTAlgorithm `json:"alg"`
+	Type      string       `json:"typ"`
+}
+
+// GetExpiry returns the expiry time of the claims
+func (c *JWTClaims) GetExpiry() time.Time {
+	return time.Unix(c.ExpiresAt, 0).UTC()
+}
+
+// SetExpiry sets the expiry time of the claims
+func (c *JWTClaims) SetExpiry(expiry time.Time) {
+	c.ExpiresAt = expiry.Unix()
+}
+
+// IsExpired returns true if the claims have no expiry or expired before the parameterized time, allowing for clock skew
+func (c *JWTClaims) IsExpired(at time.Time, clockSkew time.Duration) bool {
+	if c.ExpiresAt == 0 {
+		return true
+	}
+
+	return at.Add(-clockSkew).After(c.GetExpiry())
+}
+
+// IsAllowedEndpoint returns true if the url is any of the allowed endpoints or underneath one of them
+func (c *JWTClaims) IsAllowedEndpoint(url string) bool {
+	// clean the path first, so /api/builds/../admin isn't allowed by /api/builds
+	url = path.Clean("/" + url)
+	for _, e := range c.AllowedEndpoints {
+		e = path.Clean("/" + e)
+		if url == e || strings.HasPrefix(url, strings.TrimSuffix(e, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SignJWT returns a token for the claims signed with an hmac secret as []byte or an *rsa.PrivateKey
+func SignJWT(claims JWTClaims, key interface{}) (string, error) {
+
+	header := jwtHeader{Type: "JWT"}
+	switch k := key.(type) {
+	case []byte:
+		if len(k) == 0 {
+			return "", ErrJWTUnsupportedKey
+		}
+		header.Algorithm = JWTAlgorithmHS256
+	case *rsa.PrivateKey:
+		header.Algorithm = JWTAlgorithmRS256
+	default:
+		return "", ErrJWTUnsupportedKey
+	}
+
+	headerBytes, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	claimsBytes, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(headerBytes) + "." + base64.RawURLEncoding.EncodeToString(claimsBytes)
+
+	var signature []byte
+	switch k := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		signature, err = rsa.SignPKCS1v15(nil, k, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyJWT returns the claims of a token after checking its signature and expiry
+func VerifyJWT(token string, key interface{}, at time.Time, clockSkew time.Duration) (*JWTClaims, error) {
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTMalformed, err)
+	}
+	var header jwtHeader
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTMalformed, err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTMalformed, err)
+	}
+
+	// the algorithm is derived from the key, never from the header, to prevent algorithm confusion
+	signingInput := parts[0] + "." + parts[1]
+	switch k := key.(type) {
+	case []byte:
+		if len(k) == 0 {
+			return nil, ErrJWTUnsupportedKey
+		}
+		if header.Algorithm != JWTAlgorithmHS256 {
+			return nil, ErrJWTInvalidSignature
+		}
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, ErrJWTInvalidSignature
+		}
+	case *rsa.PublicKey:
+		if header.Algorithm != JWTAlgorithmRS256 {
+			return nil, ErrJWTInvalidSignature
+		}
+		digest := sha256.Sum256([]byte(signingInput))
+		if err = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, ErrJWTInvalidSignature
+		}
+	default:
+		return nil, ErrJWTUnsupportedKey
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTMalformed, err)
+	}
+	var claims JWTClaims
+	if err = json.Unmarshal(claimsBytes, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWTMalformed, err)
+	}
+
+	if claims.ExpiresAt == 0 {
+		return nil, ErrJWTMissingExpiry
+	}
+	if claims.IsExpired(at, clockSkew) {
+		return nil, ErrJWTExpired
+	}
+
+	return &claims, nil
+}
+
+// JWTNeedsRefresh returns true if there's no jwt or it expires within the clock skew tolerance of the parameterized time
+func (c *CIServerConfig) JWTNeedsRefresh(at time.Time, clockSkew time.Duration) bool {
+	if c.JWT == "" || c.JWTExpiry.IsZero() {
+		return true
+	}
+
+	return !at.Add(clockSkew).Before(c.JWTExpiry)
+}
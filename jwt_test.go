@@ -0,0 +1,237 @@
+package contracts
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignJWT(t *testing.T) {
+	t.Run("ReturnsTokenThatVerifiesWithSameHMACKey", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		claims := JWTClaims{
+			JobType:          JobTypeBuild,
+			JobID:            "123456",
+			RepoPath:         "github.com/estafette/estafette-ci-api",
+			AllowedEndpoints: []string{"/api/commands"},
+		}
+		claims.SetExpiry(now.Add(time.Hour))
+
+		// act
+		token, err := SignJWT(claims, []byte("my-secret"))
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		verifiedClaims, err := VerifyJWT(token, []byte("my-secret"), now, 0)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, claims, *verifiedClaims)
+	})
+
+	t.Run("ReturnsTokenThatDoesNotVerifyWithOtherHMACKey", func(t *testing.T) {
+
+		claims := JWTClaims{JobType: JobTypeBuild}
+
+		// act
+		token, err := SignJWT(claims, []byte("my-secret"))
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		_, err = VerifyJWT(token, []byte("other-secret"), time.Now(), 0)
+		assert.True(t, errors.Is(err, ErrJWTInvalidSignature))
+	})
+
+	t.Run("ReturnsTokenThatVerifiesWithRSAPublicKey", func(t *testing.T) {
+
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if !assert.Nil(t, err) {
+			return
+		}
+		claims := JWTClaims{JobType: JobTypeRelease, JobID: "15"}
+		claims.SetExpiry(time.Now().Add(time.Hour))
+
+		// act
+		token, err := SignJWT(claims, privateKey)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		verifiedClaims, err := VerifyJWT(token, &privateKey.PublicKey, time.Now(), 0)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, JobTypeRelease, verifiedClaims.JobType)
+		assert.Equal(t, "15", verifiedClaims.JobID)
+	})
+
+	t.Run("ReturnsErrorForUnsupportedKey", func(t *testing.T) {
+
+		// act
+		_, err := SignJWT(JWTClaims{}, "my-secret")
+
+		assert.True(t, errors.Is(err, ErrJWTUnsupportedKey))
+	})
+}
+
+func TestVerifyJWT(t *testing.T) {
+	t.Run("ReturnsErrorIfTokenIsExpired", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		claims := JWTClaims{}
+		claims.SetExpiry(now.Add(-time.Minute))
+		token, err := SignJWT(claims, []byte("my-secret"))
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		verifiedClaims, err := VerifyJWT(token, []byte("my-secret"), now, 0)
+
+		assert.True(t, errors.Is(err, ErrJWTExpired))
+		assert.Nil(t, verifiedClaims)
+	})
+
+	t.Run("ReturnsClaimsIfTokenIsExpiredWithinClockSkew", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		claims := JWTClaims{}
+		claims.SetExpiry(now.Add(-time.Minute))
+		token, err := SignJWT(claims, []byte("my-secret"))
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		_, err = VerifyJWT(token, []byte("my-secret"), now, 2*time.Minute)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("ReturnsErrorIfTokenHasNoExpiry", func(t *testing.T) {
+
+		token, err := SignJWT(JWTClaims{JobType: JobTypeBuild}, []byte("my-secret"))
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		verifiedClaims, err := VerifyJWT(token, []byte("my-secret"), time.Now(), 0)
+
+		assert.True(t, errors.Is(err, ErrJWTMissingExpiry))
+		assert.Nil(t, verifiedClaims)
+	})
+
+	t.Run("ReturnsErrorForEmptyHMACKey", func(t *testing.T) {
+
+		claims := JWTClaims{}
+		claims.SetExpiry(time.Now().Add(time.Hour))
+		token, err := SignJWT(claims, []byte("my-secret"))
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		_, err = VerifyJWT(token, []byte{}, time.Now(), 0)
+
+		assert.True(t, errors.Is(err, ErrJWTUnsupportedKey))
+	})
+
+	t.Run("ReturnsErrorIfHMACKeyIsUsedForRSASignedToken", func(t *testing.T) {
+
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if !assert.Nil(t, err) {
+			return
+		}
+		token, err := SignJWT(JWTClaims{}, privateKey)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		_, err = VerifyJWT(token, []byte("my-secret"), time.Now(), 0)
+
+		assert.True(t, errors.Is(err, ErrJWTInvalidSignature))
+	})
+
+	t.Run("ReturnsErrorIfTokenIsMalformed", func(t *testing.T) {
+
+		// act
+		_, err := VerifyJWT("abc.def", []byte("my-secret"), time.Now(), 0)
+
+		assert.True(t, errors.Is(err, ErrJWTMalformed))
+	})
+}
+
+func TestJWTClaimsIsAllowedEndpoint(t *testing.T) {
+	t.Run("ReturnsTrueForEndpointUnderneathAllowedEndpoint", func(t *testing.T) {
+
+		claims := JWTClaims{AllowedEndpoints: []string{"/api/commands", "/api/pipelines/github.com/estafette/estafette-ci-api/builds/"}}
+
+		// act
+		isAllowed := claims.IsAllowedEndpoint("/api/pipelines/github.com/estafette/estafette-ci-api/builds/123456/logs")
+
+		assert.True(t, isAllowed)
+	})
+
+	t.Run("ReturnsFalseForEndpointSharingOnlyAPrefix", func(t *testing.T) {
+
+		claims := JWTClaims{AllowedEndpoints: []string{"/api/commands"}}
+
+		// act
+		isAllowed := claims.IsAllowedEndpoint("/api/commandsx")
+
+		assert.False(t, isAllowed)
+	})
+
+	t.Run("ReturnsFalseForEndpointEscapingAllowedEndpointWithDotDot", func(t *testing.T) {
+
+		claims := JWTClaims{AllowedEndpoints: []string{"/api/builds"}}
+
+		// act
+		isAllowed := claims.IsAllowedEndpoint("/api/builds/../admin")
+
+		assert.False(t, isAllowed)
+	})
+}
+
+func TestCIServerConfigJWTNeedsRefresh(t *testing.T) {
+	t.Run("ReturnsTrueIfJWTIsEmpty", func(t *testing.T) {
+
+		config := CIServerConfig{}
+
+		// act
+		needsRefresh := config.JWTNeedsRefresh(time.Now(), time.Minute)
+
+		assert.True(t, needsRefresh)
+	})
+
+	t.Run("ReturnsFalseIfJWTExpiresAfterClockSkew", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		config := CIServerConfig{JWT: "abc", JWTExpiry: now.Add(10 * time.Minute)}
+
+		// act
+		needsRefresh := config.JWTNeedsRefresh(now, time.Minute)
+
+		assert.False(t, needsRefresh)
+	})
+
+	t.Run("ReturnsTrueIfJWTExpiresWithinClockSkew", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		config := CIServerConfig{JWT: "abc", JWTExpiry: now.Add(30 * time.Second)}
+
+		// act
+		needsRefresh := config.JWTNeedsRefresh(now, time.Minute)
+
+		assert.True(t, needsRefresh)
+	})
+}
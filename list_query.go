@@ -0,0 +1,597 @@
+package contracts
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ListSortField string
+
+const (
+	ListSortFieldInsertedAt ListSortField = "insertedAt"
+	ListSortFieldUpdatedAt  ListSortField = "updatedAt"
+	ListSortFieldStartedAt  ListSortField = "startedAt"
+	ListSortFieldDuration   ListSortField = "duration"
+	ListSortFieldRepoPath   ListSortField = "repoPath"
+	ListSortFieldName       ListSortField = "name"
+	ListSortFieldStatus     ListSortField = "status"
+)
+
+const (
+	listQueryDefaultPageSize = 20
+	listQueryMaxPageSize     = 100
+)
+
+// ListSort is a single sort field with its direction
+type ListSort struct {
+	Field      ListSortField `json:"field"`
+	Descending bool          `json:"descending,omitempty"`
+}
+
+// ListQuery holds the filters, sort order and pagination for list endpoints
+type ListQuery struct {
+	Statuses        []Status   `json:"statuses,omitempty"`
+	Since           *time.Time `json:"since,omitempty"`
+	Until           *time.Time `json:"until,omitempty"`
+	Labels          []Label    `json:"labels,omitempty"`
+	RepoPaths       []string   `json:"repoPaths,omitempty"`
+	Search          string     `json:"search,omitempty"`
+	RecentCommitter string     `json:"recentCommitter,omitempty"`
+	RecentReleaser  string     `json:"recentReleaser,omitempty"`
+	Groups          []string   `json:"groups,omitempty"`
+	Organizations   []string   `json:"organizations,omitempty"`
+	Sort            []ListSort `json:"sort,omitempty"`
+	PageNumber      int        `json:"pageNumber,omitempty"`
+	PageSize        int        `json:"pageSize,omitempty"`
+	Cursor          string     `json:"cursor,omitempty"`
+}
+
+// listQueryItem is the common view on pipelines, builds, releases and bots the in-memory evaluator filters and sorts on
+type listQueryItem struct {
+	status           Status
+	insertedAt       *time.Time
+	updatedAt        *time.Time
+	startedAt        *time.Time
+	duration         *time.Duration
+	labels           []Label
+	repoPath         string
+	name             string
+	groups           []*Group
+	organizations    []*Organization
+	recentCommitters []string
+	recentReleasers  []string
+}
+
+// ParseListQuery reads a list query from url query parameters like filter[status]=succeeded,failed&filter[since]=1d&sort=-insertedAt&page[size]=20
+func ParseListQuery(values url.Values, now time.Time) (query ListQuery, err error) {
+
+	if v := values.Get("filter[status]"); v != "" {
+		for _, s := range splitListQueryValue(v) {
+			query.Statuses = append(query.Statuses, Status(s))
+		}
+	}
+
+	if v := values.Get("filter[since]"); v != "" {
+		since, err := parseListQueryTime(v, now)
+		if err != nil {
+			return query, fmt.Errorf("filter[since] is invalid: %w", err)
+		}
+		query.Since = &since
+	}
+
+	if v := values.Get("filter[until]"); v != "" {
+		until, err := parseListQueryTime(v, now)
+		if err != nil {
+			return query, fmt.Errorf("filter[until] is invalid: %w", err)
+		}
+		query.Until = &until
+	}
+
+	// labels and repo paths are repeated parameters, since their values can contain commas
+	for _, l := range values["filter[labels]"] {
+		keyValue := strings.SplitN(l, "=", 2)
+		if len(keyValue) != 2 || keyValue[0] == "" {
+			return query, fmt.Errorf("filter[labels] value %v is not of format key=value", l)
+		}
+		query.Labels = append(query.Labels, Label{Key: keyValue[0], Value: keyValue[1]})
+	}
+
+	for _, p := range values["filter[repo-path]"] {
+		if p = strings.TrimSpace(p); p != "" {
+			query.RepoPaths = append(query.RepoPaths, p)
+		}
+	}
+
+	query.Search = values.Get("filter[search]")
+	query.RecentCommitter = values.Get("filter[recent-committer]")
+	query.RecentReleaser = values.Get("filter[recent-releaser]")
+
+	if v := values.Get("filter[groups]"); v != "" {
+		query.Groups = splitListQueryValue(v)
+	}
+	if v := values.Get("filter[organizations]"); v != "" {
+		query.Organizations = splitListQueryValue(v)
+	}
+
+	if v := values.Get("sort"); v != "" {
+		for _, s := range splitListQueryValue(v) {
+			if strings.HasPrefix(s, "-") {
+				query.Sort = append(query.Sort, ListSort{Field: ListSortField(strings.TrimPrefix(s, "-")), Descending: true})
+			} else {
+				query.Sort = append(query.Sort, ListSort{Field: ListSortField(s)})
+			}
+		}
+	}
+
+	if v := values.Get("page[number]"); v != "" {
+		query.PageNumber, err = strconv.Atoi(v)
+		if err != nil {
+			return query, fmt.Errorf("page[number] is invalid: %w", err)
+		}
+	}
+	if v := values.Get("page[size]"); v != "" {
+		query.PageSize, err = strconv.Atoi(v)
+		if err != nil {
+			return query, fmt.Errorf("page[size] is invalid: %w", err)
+		}
+	}
+	query.Cursor = values.Get("page[cursor]")
+
+	return query, query.Validate()
+}
+
+// Values returns the query as url query parameters understood by ParseListQuery
+func (q ListQuery) Values() url.Values {
+	values := url.Values{}
+
+	if len(q.Statuses) > 0 {
+		statuses := []string{}
+		for _, s := range q.Statuses {
+			statuses = append(statuses, string(s))
+		}
+		values.Set("filter[status]", strings.Join(statuses, ","))
+	}
+	if q.Since != nil {
+		values.Set("filter[since]", q.Since.UTC().Format(time.RFC3339Nano))
+	}
+	if q.Until != nil {
+		values.Set("filter[until]", q.Until.UTC().Format(time.RFC3339Nano))
+	}
+	for _, l := range q.Labels {
+		values.Add("filter[labels]", l.Key+"="+l.Value)
+	}
+	for _, p := range q.RepoPaths {
+		values.Add("filter[repo-path]", p)
+	}
+	if q.Search != "" {
+		values.Set("filter[search]", q.Search)
+	}
+	if q.RecentCommitter != "" {
+		values.Set("filter[recent-committer]", q.RecentCommitter)
+	}
+	if q.RecentReleaser != "" {
+		values.Set("filter[recent-releaser]", q.RecentReleaser)
+	}
+	if len(q.Groups) > 0 {
+		values.Set("filter[groups]", strings.Join(q.Groups, ","))
+	}
+	if len(q.Organizations) > 0 {
+		values.Set("filter[organizations]", strings.Join(q.Organizations, ","))
+	}
+	if len(q.Sort) > 0 {
+		sortFields := []string{}
+		for _, s := range q.Sort {
+			if s.Descending {
+				sortFields = append(sortFields, "-"+string(s.Field))
+			} else {
+				sortFields = append(sortFields, string(s.Field))
+			}
+		}
+		values.Set("sort", strings.Join(sortFields, ","))
+	}
+	if q.PageNumber > 0 {
+		values.Set("page[number]", strconv.Itoa(q.PageNumber))
+	}
+	if q.PageSize > 0 {
+		values.Set("page[size]", strconv.Itoa(q.PageSize))
+	}
+	if q.Cursor != "" {
+		values.Set("page[cursor]", q.Cursor)
+	}
+
+	return values
+}
+
+// Encode returns the query as url query string
+func (q ListQuery) Encode() string {
+	return q.Values().Encode()
+}
+
+// Validate checks whether statuses, time range, repo path patterns, sort fields and pagination are valid
+func (q ListQuery) Validate() error {
+	for _, s := range q.Statuses {
+		switch s {
+		case StatusPending, StatusAwaitingApproval, StatusRunning, StatusSucceeded, StatusFailed, StatusCanceling, StatusCanceled:
+		default:
+			return fmt.Errorf("Status %q is not a valid filter", s)
+		}
+	}
+
+	if q.Since != nil && q.Until != nil && q.Until.Before(*q.Since) {
+		return fmt.Errorf("Until %v is before since %v", q.Until, q.Since)
+	}
+
+	for _, p := range q.RepoPaths {
+		if _, err := compileRepoPathPattern(p); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range q.Sort {
+		switch s.Field {
+		case ListSortFieldInsertedAt, ListSortFieldUpdatedAt, ListSortFieldStartedAt, ListSortFieldDuration, ListSortFieldRepoPath, ListSortFieldName, ListSortFieldStatus:
+		default:
+			return fmt.Errorf("Sort field %q is not supported", s.Field)
+		}
+	}
+
+	if q.PageNumber < 0 {
+		return fmt.Errorf("Page number %v is invalid", q.PageNumber)
+	}
+	if q.PageSize < 0 || q.PageSize > listQueryMaxPageSize {
+		return fmt.Errorf("Page size %v is invalid, it should be between 1 and %v", q.PageSize, listQueryMaxPageSize)
+	}
+	if q.Cursor != "" {
+		if q.PageNumber > 0 {
+			return fmt.Errorf("Page number and cursor can't be combined")
+		}
+		if _, err := DecodeCursor(q.Cursor); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetPageNumber returns the page number, defaulting to the first page
+func (q ListQuery) GetPageNumber() int {
+	if q.PageNumber <= 0 {
+		return 1
+	}
+	return q.PageNumber
+}
+
+// GetPageSize returns the page size, defaulting to 20
+func (q ListQuery) GetPageSize() int {
+	if q.PageSize <= 0 {
+		return listQueryDefaultPageSize
+	}
+	return q.PageSize
+}
+
+// ApplyToPipelines returns the pipelines matching the filters, in sort order
+func (q ListQuery) ApplyToPipelines(pipelines []*Pipeline) ([]*Pipeline, error) {
+	return applyListQuery(q, pipelines, func(p *Pipeline) listQueryItem {
+		return listQueryItem{
+			status:           p.BuildStatus,
+			insertedAt:       &p.InsertedAt,
+			updatedAt:        &p.UpdatedAt,
+			startedAt:        p.StartedAt,
+			duration:         &p.Duration,
+			labels:           p.Labels,
+			repoPath:         p.GetFullRepoPath(),
+			name:             p.RepoName,
+			groups:           p.Groups,
+			organizations:    p.Organizations,
+			recentCommitters: p.RecentCommitters,
+			recentReleasers:  p.RecentReleasers,
+		}
+	})
+}
+
+// ApplyToBuilds returns the builds matching the filters, in sort order
+func (q ListQuery) ApplyToBuilds(builds []*Build) ([]*Build, error) {
+	return applyListQuery(q, builds, func(b *Build) listQueryItem {
+		committers := []string{}
+		for _, c := range b.Commits {
+			committers = append(committers, c.Author.Email)
+		}
+		return listQueryItem{
+			status:           b.BuildStatus,
+			insertedAt:       &b.InsertedAt,
+			updatedAt:        &b.UpdatedAt,
+			startedAt:        b.StartedAt,
+			duration:         &b.Duration,
+			labels:           b.Labels,
+			repoPath:         b.GetFullRepoPath(),
+			name:             b.RepoName,
+			groups:           b.Groups,
+			organizations:    b.Organizations,
+			recentCommitters: committers,
+		}
+	})
+}
+
+// ApplyToReleases returns the releases matching the filters, in sort order
+func (q ListQuery) ApplyToReleases(releases []*Release) ([]*Release, error) {
+	return applyListQuery(q, releases, func(r *Release) listQueryItem {
+		return listQueryItem{
+			status:        r.ReleaseStatus,
+			insertedAt:    r.InsertedAt,
+			updatedAt:     r.UpdatedAt,
+			startedAt:     r.StartedAt,
+			duration:      r.Duration,
+			repoPath:      r.GetFullRepoPath(),
+			name:          r.Name,
+			groups:        r.Groups,
+			organizations: r.Organizations,
+		}
+	})
+}
+
+// ApplyToBots returns the bots matching the filters, in sort order
+func (q ListQuery) ApplyToBots(bots []*Bot) ([]*Bot, error) {
+	return applyListQuery(q, bots, func(b *Bot) listQueryItem {
+		return listQueryItem{
+			status:        b.BotStatus,
+			insertedAt:    b.InsertedAt,
+			updatedAt:     b.UpdatedAt,
+			startedAt:     b.StartedAt,
+			duration:      b.Duration,
+			repoPath:      b.GetFullRepoPath(),
+			name:          b.Name,
+			groups:        b.Groups,
+			organizations: b.Organizations,
+		}
+	})
+}
+
+// applyListQuery filters and sorts items, where filters on fields a type doesn't have exclude all items
+func applyListQuery[T any](q ListQuery, items []*T, toItem func(*T) listQueryItem) ([]*T, error) {
+
+	repoPathPatterns := []*regexp.Regexp{}
+	for _, p := range q.RepoPaths {
+		pattern, err := compileRepoPathPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		repoPathPatterns = append(repoPathPatterns, pattern)
+	}
+
+	type filteredItem struct {
+		item  *T
+		query listQueryItem
+	}
+	filteredItems := []filteredItem{}
+	for _, i := range items {
+		if i == nil {
+			continue
+		}
+		qi := toItem(i)
+		if q.matches(qi, repoPathPatterns) {
+			filteredItems = append(filteredItems, filteredItem{item: i, query: qi})
+		}
+	}
+
+	sort.SliceStable(filteredItems, func(i, j int) bool {
+		for _, s := range q.Sort {
+			comparison := compareListQueryItems(filteredItems[i].query, filteredItems[j].query, s.Field)
+			if comparison == 0 {
+				continue
+			}
+			if s.Descending {
+				return comparison > 0
+			}
+			return comparison < 0
+		}
+		return false
+	})
+
+	result := make([]*T, 0, len(filteredItems))
+	for _, fi := range filteredItems {
+		result = append(result, fi.item)
+	}
+
+	return result, nil
+}
+
+// compileRepoPathPattern returns the regular expression matching complete repo paths for a pattern
+func compileRepoPathPattern(pattern string) (*regexp.Regexp, error) {
+	compiledPattern, err := regexp.Compile(fmt.Sprintf("^(%v)$", strings.TrimSpace(pattern)))
+	if err != nil {
+		return nil, fmt.Errorf("Repo path pattern %v is invalid: %w", pattern, err)
+	}
+
+	return compiledPattern, nil
+}
+
+func (q ListQuery) matches(item listQueryItem, repoPathPatterns []*regexp.Regexp) bool {
+	if len(q.Statuses) > 0 {
+		isMatch := false
+		for _, s := range q.Statuses {
+			if s == item.status {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	if q.Since != nil && (item.insertedAt == nil || item.insertedAt.Before(*q.Since)) {
+		return false
+	}
+	if q.Until != nil && (item.insertedAt == nil || item.insertedAt.After(*q.Until)) {
+		return false
+	}
+
+	for _, l := range q.Labels {
+		hasLabel := false
+		for _, il := range item.labels {
+			if il.Key == l.Key && il.Value == l.Value {
+				hasLabel = true
+				break
+			}
+		}
+		if !hasLabel {
+			return false
+		}
+	}
+
+	if len(repoPathPatterns) > 0 {
+		isMatch := false
+		for _, p := range repoPathPatterns {
+			if p.MatchString(item.repoPath) {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	if q.Search != "" {
+		search := strings.ToLower(q.Search)
+		if !strings.Contains(strings.ToLower(item.repoPath), search) && !strings.Contains(strings.ToLower(item.name), search) {
+			return false
+		}
+	}
+
+	if q.RecentCommitter != "" && !containsString(item.recentCommitters, q.RecentCommitter) {
+		return false
+	}
+	if q.RecentReleaser != "" && !containsString(item.recentReleasers, q.RecentReleaser) {
+		return false
+	}
+
+	if len(q.Groups) > 0 {
+		isMatch := false
+		for _, g := range item.groups {
+			if g != nil && (containsString(q.Groups, g.Name) || containsString(q.Groups, g.ID)) {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	if len(q.Organizations) > 0 {
+		isMatch := false
+		for _, o := range item.organizations {
+			if o != nil && (containsString(q.Organizations, o.Name) || containsString(q.Organizations, o.ID)) {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	return true
+}
+
+// compareListQueryItems returns -1, 0 or 1, sorting items without a value for the field first
+func compareListQueryItems(a, b listQueryItem, field ListSortField) int {
+	switch field {
+	case ListSortFieldInsertedAt:
+		return compareTimePointers(a.insertedAt, b.insertedAt)
+	case ListSortFieldUpdatedAt:
+		return compareTimePointers(a.updatedAt, b.updatedAt)
+	case ListSortFieldStartedAt:
+		return compareTimePointers(a.startedAt, b.startedAt)
+	case ListSortFieldDuration:
+		switch {
+		case a.duration == nil && b.duration == nil:
+			return 0
+		case a.duration == nil:
+			return -1
+		case b.duration == nil:
+			return 1
+		}
+		return compareOrdered(*a.duration, *b.duration)
+	case ListSortFieldRepoPath:
+		return strings.Compare(a.repoPath, b.repoPath)
+	case ListSortFieldName:
+		return strings.Compare(a.name, b.name)
+	case ListSortFieldStatus:
+		return strings.Compare(string(a.status), string(b.status))
+	}
+
+	return 0
+}
+
+func compareTimePointers(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	case a.Before(*b):
+		return -1
+	case a.After(*b):
+		return 1
+	}
+
+	return 0
+}
+
+func compareOrdered[T ~int | ~int64 | ~float64 | ~string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// parseListQueryTime accepts RFC3339 times with optional fractional seconds or relative durations like 30m, 12h, 7d or 2w before now
+func parseListQueryTime(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if len(value) > 1 {
+		unit := value[len(value)-1:]
+		amount, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && amount >= 0 {
+			switch unit {
+			case "d":
+				return now.AddDate(0, 0, -amount), nil
+			case "w":
+				return now.AddDate(0, 0, -7*amount), nil
+			}
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v is neither an RFC3339 time nor a relative duration like 1h, 7d or 2w", value)
+	}
+
+	return now.Add(-duration), nil
+}
+
+func splitListQueryValue(value string) []string {
+	values := []string{}
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
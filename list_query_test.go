@@ -0,0 +1,301 @@
+package contracts
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseListQuery(t *testing.T) {
+	t.Run("ReturnsFiltersSortAndPagination", func(t *testing.T) {
+
+		now := time.Date(2021, 4, 17, 8, 0, 0, 0, time.UTC)
+		values, err := url.ParseQuery("filter[status]=succeeded,failed&filter[since]=1d&filter[labels]=app=estafette-ci-api&filter[labels]=team=estafette&filter[repo-path]=github.com/estafette/.*&filter[groups]=estafette&sort=-insertedAt,repoPath&page[number]=2&page[size]=50")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		query, err := ParseListQuery(values, now)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, []Status{StatusSucceeded, StatusFailed}, query.Statuses)
+		assert.Equal(t, time.Date(2021, 4, 16, 8, 0, 0, 0, time.UTC), *query.Since)
+		assert.Nil(t, query.Until)
+		assert.Equal(t, []Label{{Key: "app", Value: "estafette-ci-api"}, {Key: "team", Value: "estafette"}}, query.Labels)
+		assert.Equal(t, []string{"github.com/estafette/.*"}, query.RepoPaths)
+		assert.Equal(t, []string{"estafette"}, query.Groups)
+		assert.Equal(t, []ListSort{{Field: ListSortFieldInsertedAt, Descending: true}, {Field: ListSortFieldRepoPath}}, query.Sort)
+		assert.Equal(t, 2, query.GetPageNumber())
+		assert.Equal(t, 50, query.GetPageSize())
+	})
+
+	t.Run("ReturnsQueryThatWasEncoded", func(t *testing.T) {
+
+		since := time.Date(2021, 4, 10, 0, 0, 0, 123456789, time.UTC)
+		until := time.Date(2021, 4, 17, 0, 0, 0, 0, time.UTC)
+		query := ListQuery{
+			Statuses:        []Status{StatusRunning},
+			Since:           &since,
+			Until:           &until,
+			Labels:          []Label{{Key: "app", Value: "estafette-ci-api"}, {Key: "environments", Value: "staging,production"}},
+			RepoPaths:       []string{"github.com/estafette/.*", "bitbucket.org/xivart/estafette-ci-(api|web){1,2}"},
+			Search:          "estafette",
+			RecentCommitter: "me@estafette.io",
+			RecentReleaser:  "you@estafette.io",
+			Groups:          []string{"estafette"},
+			Organizations:   []string{"xivart"},
+			Sort:            []ListSort{{Field: ListSortFieldDuration, Descending: true}},
+			PageSize:        10,
+			Cursor:          NewTimeCursor(until, "123").Encode(),
+		}
+		values, err := url.ParseQuery(query.Encode())
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		decodedQuery, err := ParseListQuery(values, time.Now())
+
+		assert.Nil(t, err)
+		assert.Equal(t, query, decodedQuery)
+	})
+
+	t.Run("ReturnsDefaultPaginationForEmptyQuery", func(t *testing.T) {
+
+		// act
+		query, err := ParseListQuery(url.Values{}, time.Now())
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, query.GetPageNumber())
+		assert.Equal(t, 20, query.GetPageSize())
+	})
+
+	t.Run("ReturnsErrorForInvalidValues", func(t *testing.T) {
+
+		invalidQueries := []string{
+			"filter[status]=done",
+			"filter[since]=yesterday",
+			"filter[labels]=app",
+			"filter[repo-path]=github.com/(estafette",
+			"filter[since]=2021-04-17T00:00:00Z&filter[until]=2021-04-10T00:00:00Z",
+			"sort=-color",
+			"page[size]=1000",
+			"page[number]=two",
+			"page[cursor]=not a cursor",
+		}
+
+		for _, q := range invalidQueries {
+			values, err := url.ParseQuery(q)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			// act
+			_, err = ParseListQuery(values, time.Now())
+
+			assert.NotNil(t, err, q)
+		}
+	})
+}
+
+func TestListQueryApplyToBuilds(t *testing.T) {
+	t.Run("ReturnsBuildsMatchingAllFiltersInSortOrder", func(t *testing.T) {
+
+		builds := []*Build{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC), Labels: []Label{{Key: "team", Value: "estafette"}}},
+			{ID: "2", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-web", BuildStatus: StatusFailed, InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC), Labels: []Label{{Key: "team", Value: "estafette"}}},
+			{ID: "3", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", BuildStatus: StatusRunning, InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC), Labels: []Label{{Key: "team", Value: "estafette"}}},
+			{ID: "4", RepoSource: "bitbucket.org", RepoOwner: "xivart", RepoName: "estafette-ci-api", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC), Labels: []Label{{Key: "team", Value: "estafette"}}},
+			{ID: "5", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC)},
+		}
+		query := ListQuery{
+			Statuses:  []Status{StatusSucceeded, StatusFailed},
+			Labels:    []Label{{Key: "team", Value: "estafette"}},
+			RepoPaths: []string{"github.com/estafette/.*"},
+			Sort:      []ListSort{{Field: ListSortFieldInsertedAt, Descending: true}},
+		}
+
+		// act
+		filteredBuilds, err := query.ApplyToBuilds(builds)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(filteredBuilds)) {
+			return
+		}
+		assert.Equal(t, "2", filteredBuilds[0].ID)
+		assert.Equal(t, "1", filteredBuilds[1].ID)
+	})
+
+	t.Run("ReturnsBuildsWithinTimeRangeWithCommitsByRecentCommitter", func(t *testing.T) {
+
+		since := time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)
+		until := time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)
+		builds := []*Build{
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC), Commits: []GitCommit{{Author: GitAuthor{Email: "me@estafette.io"}}}},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC), Commits: []GitCommit{{Author: GitAuthor{Email: "me@estafette.io"}}}},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC), Commits: []GitCommit{{Author: GitAuthor{Email: "you@estafette.io"}}}},
+			{ID: "4", InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC), Commits: []GitCommit{{Author: GitAuthor{Email: "me@estafette.io"}}}},
+		}
+		query := ListQuery{
+			Since:           &since,
+			Until:           &until,
+			RecentCommitter: "me@estafette.io",
+		}
+
+		// act
+		filteredBuilds, err := query.ApplyToBuilds(builds)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(filteredBuilds)) {
+			return
+		}
+		assert.Equal(t, "2", filteredBuilds[0].ID)
+	})
+
+	t.Run("ReturnsErrorForInvalidRepoPathPattern", func(t *testing.T) {
+
+		builds := []*Build{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api"},
+		}
+		query := ListQuery{
+			RepoPaths: []string{"github.com/(estafette"},
+		}
+
+		// act
+		_, err := query.ApplyToBuilds(builds)
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForSameRepoPathPatternsAsValidate", func(t *testing.T) {
+
+		builds := []*Build{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api"},
+		}
+		patterns := []string{"github.com/estafette/.*", "github.com/(estafette", "a)|(b", " github.com/estafette/estafette-ci-api "}
+
+		for _, p := range patterns {
+			query := ListQuery{
+				RepoPaths: []string{p},
+			}
+
+			// act
+			validateErr := query.Validate()
+			_, applyErr := query.ApplyToBuilds(builds)
+
+			assert.Equal(t, validateErr == nil, applyErr == nil, p)
+		}
+	})
+
+	t.Run("SkipsNilBuilds", func(t *testing.T) {
+
+		builds := []*Build{
+			nil,
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api"},
+		}
+		query := ListQuery{
+			Sort: []ListSort{{Field: ListSortFieldName}},
+		}
+
+		// act
+		filteredBuilds, err := query.ApplyToBuilds(builds)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(filteredBuilds)) {
+			return
+		}
+		assert.Equal(t, "1", filteredBuilds[0].ID)
+	})
+}
+
+func TestListQueryApplyToPipelines(t *testing.T) {
+	t.Run("ReturnsPipelinesMatchingSearchAndGroupSortedByMultipleFields", func(t *testing.T) {
+
+		pipelines := []*Pipeline{
+			{ID: "1", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-web", Duration: 2 * time.Minute, Groups: []*Group{{ID: "g1", Name: "estafette"}}},
+			{ID: "2", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", Duration: 2 * time.Minute, Groups: []*Group{{ID: "g1", Name: "estafette"}}},
+			{ID: "3", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-db", Duration: 5 * time.Minute, Groups: []*Group{{ID: "g1", Name: "estafette"}}},
+			{ID: "4", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-cron", Duration: 1 * time.Minute, Groups: []*Group{{ID: "g2", Name: "xivart"}}},
+			{ID: "5", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "gke-node-pool-shifter", Duration: 1 * time.Minute, Groups: []*Group{{ID: "g1", Name: "estafette"}}},
+		}
+		query := ListQuery{
+			Search: "CI",
+			Groups: []string{"estafette"},
+			Sort:   []ListSort{{Field: ListSortFieldDuration, Descending: true}, {Field: ListSortFieldName}},
+		}
+
+		// act
+		filteredPipelines, err := query.ApplyToPipelines(pipelines)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 3, len(filteredPipelines)) {
+			return
+		}
+		assert.Equal(t, "3", filteredPipelines[0].ID)
+		assert.Equal(t, "2", filteredPipelines[1].ID)
+		assert.Equal(t, "1", filteredPipelines[2].ID)
+	})
+}
+
+func TestListQueryApplyToReleases(t *testing.T) {
+	t.Run("ExcludesReleasesWithoutInsertedAtForTimeRange", func(t *testing.T) {
+
+		since := time.Date(2021, 4, 17, 8, 0, 0, 0, time.UTC)
+		insertedAt := time.Date(2021, 4, 17, 9, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "1", Name: "production", InsertedAt: &insertedAt},
+			{ID: "2", Name: "production"},
+		}
+		query := ListQuery{
+			Since: &since,
+		}
+
+		// act
+		filteredReleases, err := query.ApplyToReleases(releases)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(filteredReleases)) {
+			return
+		}
+		assert.Equal(t, "1", filteredReleases[0].ID)
+	})
+
+	t.Run("ReturnsNoReleasesForRecentCommitterFilter", func(t *testing.T) {
+
+		releases := []*Release{
+			{ID: "1", Name: "production"},
+		}
+		query := ListQuery{
+			RecentCommitter: "me@estafette.io",
+		}
+
+		// act
+		filteredReleases, err := query.ApplyToReleases(releases)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(filteredReleases))
+	})
+}
+
+func TestListQueryApplyToBots(t *testing.T) {
+	t.Run("ReturnsBotsMatchingStatusAndOrganization", func(t *testing.T) {
+
+		bots := []*Bot{
+			{ID: "1", Name: "stale-issues", BotStatus: StatusSucceeded, Organizations: []*Organization{{ID: "o1", Name: "xivart"}}},
+			{ID: "2", Name: "stale-issues", BotStatus: StatusFailed, Organizations: []*Organization{{ID: "o1", Name: "xivart"}}},
+			{ID: "3", Name: "stale-issues", BotStatus: StatusSucceeded},
+		}
+		query := ListQuery{
+			Statuses:      []Status{StatusSucceeded},
+			Organizations: []string{"o1"},
+		}
+
+		// act
+		filteredBots, err := query.ApplyToBots(bots)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(filteredBots)) {
+			return
+		}
+		assert.Equal(t, "1", filteredBots[0].ID)
+	})
+}
@@ -1,15 +1,198 @@
 package contracts
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
 // ListResponse is a container for paginated filtered list items
-type ListResponse struct {
-	Items      []interface{} `json:"items"`
-	Pagination Pagination    `json:"pagination"`
+type ListResponse[T any] struct {
+	Items      []T        `json:"items"`
+	Pagination Pagination `json:"pagination"`
 }
 
-// Pagination indicates the current page, the size of the pages and total pages / items
+// UntypedListResponse has the shape of the ListResponse from before it had typed items
+type UntypedListResponse = ListResponse[interface{}]
+
+// Pagination indicates the current page, the size of the pages and total pages / items, or the cursors of the next and previous pages
 type Pagination struct {
-	Page       int `json:"page"`
-	Size       int `json:"size"`
-	TotalPages int `json:"totalPages"`
-	TotalItems int `json:"totalItems"`
+	Page           int    `json:"page"`
+	Size           int    `json:"size"`
+	TotalPages     int    `json:"totalPages"`
+	TotalItems     int    `json:"totalItems"`
+	NextCursor     string `json:"nextCursor,omitempty"`
+	PreviousCursor string `json:"previousCursor,omitempty"`
+}
+
+type CursorDirection string
+
+const (
+	CursorDirectionNext     CursorDirection = "next"
+	CursorDirectionPrevious CursorDirection = "previous"
+)
+
+// Cursor points at the item a page starts after or before by its sort key and id, so items inserted between page requests aren't skipped or repeated
+type Cursor struct {
+	SortKey   string          `json:"k"`
+	ID        string          `json:"i"`
+	Direction CursorDirection `json:"d,omitempty"`
+}
+
+// NewListResponse returns a page based list response
+func NewListResponse[T any](items []T, page, size, totalItems int) ListResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	totalPages := 0
+	if size > 0 {
+		totalPages = (totalItems + size - 1) / size
+	}
+
+	return ListResponse[T]{
+		Items: items,
+		Pagination: Pagination{
+			Page:       page,
+			Size:       size,
+			TotalPages: totalPages,
+			TotalItems: totalItems,
+		},
+	}
+}
+
+// NewCursorListResponse returns a cursor based list response for one item more than the page size, so it can tell whether there's a next page
+func NewCursorListResponse[T any](items []T, size int, hasPrevious bool, getCursor func(T) Cursor) ListResponse[T] {
+	hasNext := len(items) > size
+	if hasNext {
+		items = items[:size]
+	}
+
+	return newCursorListResponse(items, size, hasPrevious, hasNext, getCursor)
+}
+
+// PaginateByCursor returns the page after or before the cursor from sorted items, or the first page for an empty token
+func PaginateByCursor[T any](items []T, token string, size int, descending bool, getCursor func(T) Cursor) (ListResponse[T], error) {
+	if size <= 0 {
+		return ListResponse[T]{}, fmt.Errorf("Page size %v is invalid", size)
+	}
+
+	start := 0
+	if token != "" {
+		cursor, err := DecodeCursor(token)
+		if err != nil {
+			return ListResponse[T]{}, err
+		}
+
+		// seek instead of looking up the item itself, so a page can still be retrieved after the cursor item is deleted
+		order := 1
+		if descending {
+			order = -1
+		}
+
+		if cursor.Direction == CursorDirectionPrevious {
+			end := len(items)
+			for i, item := range items {
+				if order*compareCursors(getCursor(item), cursor) >= 0 {
+					end = i
+					break
+				}
+			}
+			start = end - size
+			if start < 0 {
+				start = 0
+			}
+			return newCursorListResponse(items[start:end], size, start > 0, end < len(items), getCursor), nil
+		}
+
+		start = len(items)
+		for i, item := range items {
+			if order*compareCursors(getCursor(item), cursor) > 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := start + size
+	if end > len(items) {
+		end = len(items)
+	}
+
+	return newCursorListResponse(items[start:end], size, start > 0, end < len(items), getCursor), nil
+}
+
+func newCursorListResponse[T any](items []T, size int, hasPrevious, hasNext bool, getCursor func(T) Cursor) ListResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	response := ListResponse[T]{
+		Items: items,
+		Pagination: Pagination{
+			Size: size,
+		},
+	}
+
+	if len(items) > 0 {
+		if hasNext {
+			cursor := getCursor(items[len(items)-1])
+			cursor.Direction = CursorDirectionNext
+			response.Pagination.NextCursor = cursor.Encode()
+		}
+		if hasPrevious {
+			cursor := getCursor(items[0])
+			cursor.Direction = CursorDirectionPrevious
+			response.Pagination.PreviousCursor = cursor.Encode()
+		}
+	}
+
+	return response
+}
+
+// cursorTimeFormat is RFC3339 with a fixed number of fractional digits, so sort keys sort like the times they hold
+const cursorTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+// NewTimeCursor returns a cursor for items sorted by time, like builds by insertedAt
+func NewTimeCursor(sortKey time.Time, id string) Cursor {
+	return Cursor{
+		SortKey: sortKey.UTC().Format(cursorTimeFormat),
+		ID:      id,
+	}
+}
+
+// Encode returns the cursor as opaque token
+func (c Cursor) Encode() string {
+	bytes, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// DecodeCursor returns the cursor for an opaque token created by Encode
+func DecodeCursor(token string) (cursor Cursor, err error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, fmt.Errorf("Cursor %v is invalid: %w", token, err)
+	}
+	if err = json.Unmarshal(bytes, &cursor); err != nil {
+		return cursor, fmt.Errorf("Cursor %v is invalid: %w", token, err)
+	}
+
+	return cursor, nil
+}
+
+// GetSortKeyTime returns the sort key of a cursor created by NewTimeCursor
+func (c Cursor) GetSortKeyTime() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, c.SortKey)
+}
+
+// compareCursors orders cursors by sort key and then by id
+func compareCursors(a, b Cursor) int {
+	if a.SortKey != b.SortKey {
+		return strings.Compare(a.SortKey, b.SortKey)
+	}
+
+	return strings.Compare(a.ID, b.ID)
 }
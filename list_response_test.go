@@ -0,0 +1,295 @@
+package contracts
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListResponse(t *testing.T) {
+	t.Run("UnmarshalsPageBasedJSONIntoTypedItems", func(t *testing.T) {
+
+		body := `{"items":[{"id":"5","repoSource":"github.com","repoOwner":"estafette","repoName":"estafette-ci-api"}],"pagination":{"page":2,"size":20,"totalPages":3,"totalItems":41}}`
+		var response ListResponse[Pipeline]
+
+		// act
+		err := json.Unmarshal([]byte(body), &response)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(response.Items)) {
+			return
+		}
+		assert.Equal(t, "github.com/estafette/estafette-ci-api", response.Items[0].GetFullRepoPath())
+		assert.Equal(t, Pagination{Page: 2, Size: 20, TotalPages: 3, TotalItems: 41}, response.Pagination)
+	})
+
+	t.Run("UnmarshalsPageBasedJSONIntoUntypedItems", func(t *testing.T) {
+
+		body := `{"items":[{"id":"5"}],"pagination":{"page":1,"size":20,"totalPages":1,"totalItems":1}}`
+		var response UntypedListResponse
+
+		// act
+		err := json.Unmarshal([]byte(body), &response)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(response.Items)) {
+			return
+		}
+		assert.Equal(t, map[string]interface{}{"id": "5"}, response.Items[0])
+	})
+
+	t.Run("MarshalsPageBasedJSONWithoutCursors", func(t *testing.T) {
+
+		response := NewListResponse([]Label{{Key: "app", Value: "estafette-ci-api"}}, 1, 20, 21)
+
+		// act
+		bytes, err := json.Marshal(response)
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"items":[{"key":"app","value":"estafette-ci-api"}],"pagination":{"page":1,"size":20,"totalPages":2,"totalItems":21}}`, string(bytes))
+	})
+
+	t.Run("MarshalsEmptyItemsAsEmptyArray", func(t *testing.T) {
+
+		response := NewListResponse[Build](nil, 1, 20, 0)
+
+		// act
+		bytes, err := json.Marshal(response)
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"items":[],"pagination":{"page":1,"size":20,"totalPages":0,"totalItems":0}}`, string(bytes))
+	})
+}
+
+func TestDecodeCursor(t *testing.T) {
+	t.Run("ReturnsCursorThatWasEncoded", func(t *testing.T) {
+
+		cursor := NewTimeCursor(time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC), "123456")
+
+		// act
+		decodedCursor, err := DecodeCursor(cursor.Encode())
+
+		assert.Nil(t, err)
+		assert.Equal(t, cursor, decodedCursor)
+		sortKey, err := decodedCursor.GetSortKeyTime()
+		assert.Nil(t, err)
+		assert.Equal(t, time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC), sortKey)
+	})
+
+	t.Run("ReturnsLastItemOfDescendingListAfterCursor", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 7, 0, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+		cursor := NewTimeCursor(time.Date(2021, 4, 17, 8, 0, 0, 0, time.UTC), "2")
+		cursor.Direction = CursorDirectionNext
+
+		// act
+		lastPage, err := PaginateByCursor(builds, cursor.Encode(), 2, true, getCursor)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(lastPage.Items)) {
+			return
+		}
+		assert.Equal(t, "1", lastPage.Items[0].ID)
+	})
+
+	t.Run("ReturnsItemsAfterCursorForAscendingList", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+		firstPage, err := PaginateByCursor(builds, "", 2, false, getCursor)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		secondPage, err := PaginateByCursor(builds, firstPage.Pagination.NextCursor, 2, false, getCursor)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(secondPage.Items)) {
+			return
+		}
+		assert.Equal(t, "3", secondPage.Items[0].ID)
+	})
+
+	t.Run("ReturnsErrorForInvalidToken", func(t *testing.T) {
+
+		// act
+		_, err := DecodeCursor("not a cursor")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestPaginateByCursor(t *testing.T) {
+	t.Run("ReturnsFirstPageWithNextCursorOnly", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "5", InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC)},
+			{ID: "4", InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+
+		// act
+		response, err := PaginateByCursor(builds, "", 2, true, getCursor)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(response.Items)) {
+			return
+		}
+		assert.Equal(t, "5", response.Items[0].ID)
+		assert.Equal(t, "4", response.Items[1].ID)
+		assert.NotEqual(t, "", response.Pagination.NextCursor)
+		assert.Equal(t, "", response.Pagination.PreviousCursor)
+	})
+
+	t.Run("DoesNotRepeatItemsWhenNewItemsArriveBetweenRequests", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "5", InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC)},
+			{ID: "4", InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+		firstPage, err := PaginateByCursor(builds, "", 2, true, getCursor)
+		if !assert.Nil(t, err) {
+			return
+		}
+		builds = append([]Build{{ID: "6", InsertedAt: time.Date(2021, 4, 17, 8, 6, 0, 0, time.UTC)}}, builds...)
+
+		// act
+		secondPage, err := PaginateByCursor(builds, firstPage.Pagination.NextCursor, 2, true, getCursor)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(secondPage.Items)) {
+			return
+		}
+		assert.Equal(t, "3", secondPage.Items[0].ID)
+		assert.Equal(t, "2", secondPage.Items[1].ID)
+		assert.NotEqual(t, "", secondPage.Pagination.NextCursor)
+		assert.NotEqual(t, "", secondPage.Pagination.PreviousCursor)
+
+		lastPage, err := PaginateByCursor(builds, secondPage.Pagination.NextCursor, 2, true, getCursor)
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(lastPage.Items)) {
+			return
+		}
+		assert.Equal(t, "1", lastPage.Items[0].ID)
+		assert.Equal(t, "", lastPage.Pagination.NextCursor)
+	})
+
+	t.Run("ReturnsPreviousPageForPreviousCursor", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "5", InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC)},
+			{ID: "4", InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+		firstPage, _ := PaginateByCursor(builds, "", 2, true, getCursor)
+		secondPage, _ := PaginateByCursor(builds, firstPage.Pagination.NextCursor, 2, true, getCursor)
+
+		// act
+		previousPage, err := PaginateByCursor(builds, secondPage.Pagination.PreviousCursor, 2, true, getCursor)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(previousPage.Items)) {
+			return
+		}
+		assert.Equal(t, "5", previousPage.Items[0].ID)
+		assert.Equal(t, "4", previousPage.Items[1].ID)
+		assert.Equal(t, "", previousPage.Pagination.PreviousCursor)
+	})
+
+	t.Run("ReturnsItemsAfterCursorIfCursorItemNoLongerExists", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "5", InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC)},
+			{ID: "4", InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+		firstPage, err := PaginateByCursor(builds, "", 2, true, getCursor)
+		if !assert.Nil(t, err) {
+			return
+		}
+		builds = append(builds[:1], builds[2:]...)
+
+		// act
+		secondPage, err := PaginateByCursor(builds, firstPage.Pagination.NextCursor, 2, true, getCursor)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(secondPage.Items)) {
+			return
+		}
+		assert.Equal(t, "3", secondPage.Items[0].ID)
+		assert.Equal(t, "2", secondPage.Items[1].ID)
+	})
+
+	t.Run("ReturnsItemsBeforeCursorIfCursorItemNoLongerExists", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "5", InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC)},
+			{ID: "4", InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+		cursor := getCursor(builds[2])
+		cursor.Direction = CursorDirectionPrevious
+		builds = append(builds[:2], builds[3:]...)
+
+		// act
+		previousPage, err := PaginateByCursor(builds, cursor.Encode(), 2, true, getCursor)
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(previousPage.Items)) {
+			return
+		}
+		assert.Equal(t, "5", previousPage.Items[0].ID)
+		assert.Equal(t, "4", previousPage.Items[1].ID)
+		assert.Equal(t, "", previousPage.Pagination.PreviousCursor)
+		assert.NotEqual(t, "", previousPage.Pagination.NextCursor)
+	})
+
+	t.Run("ReturnsErrorForInvalidToken", func(t *testing.T) {
+
+		builds := []Build{
+			{ID: "5", InsertedAt: time.Date(2021, 4, 17, 8, 5, 0, 0, time.UTC)},
+			{ID: "4", InsertedAt: time.Date(2021, 4, 17, 8, 4, 0, 0, time.UTC)},
+			{ID: "3", InsertedAt: time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)},
+			{ID: "2", InsertedAt: time.Date(2021, 4, 17, 8, 2, 0, 0, time.UTC)},
+			{ID: "1", InsertedAt: time.Date(2021, 4, 17, 8, 1, 0, 0, time.UTC)},
+		}
+		getCursor := func(build Build) Cursor {
+			return NewTimeCursor(build.InsertedAt, build.ID)
+		}
+
+		// act
+		_, err := PaginateByCursor(builds, "not a cursor", 2, true, getCursor)
+
+		assert.NotNil(t, err)
+	})
+}
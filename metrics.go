@@ -0,0 +1,221 @@
+package contracts
+
+import (
+	"sort"
+	"time"
+)
+
+// PipelineMetrics contains health and DORA metrics over the builds and releases of a pipeline in a period
+type PipelineMetrics struct {
+	Since            time.Time                     `json:"since"`
+	Until            time.Time                     `json:"until"`
+	BuildSuccessRate *float64                      `json:"buildSuccessRate,omitempty"`
+	Flakiness        *float64                      `json:"flakiness,omitempty"`
+	Targets          map[string]*DeploymentMetrics `json:"targets,omitempty"`
+}
+
+// DeploymentMetrics contains the DORA metrics for a single release target in a period
+type DeploymentMetrics struct {
+	Since             time.Time      `json:"since"`
+	Until             time.Time      `json:"until"`
+	Deployments       int            `json:"deployments"`
+	DeploymentsPerDay float64        `json:"deploymentsPerDay"`
+	MedianLeadTime    *time.Duration `json:"medianLeadTime,omitempty"`
+	ChangeFailureRate *float64       `json:"changeFailureRate,omitempty"`
+	MeanTimeToRestore *time.Duration `json:"meanTimeToRestore,omitempty"`
+}
+
+// ComputePipelineMetrics returns the build success rate and flakiness for builds inserted in the period, and the deployment metrics for each release target with releases inserted in the period
+func ComputePipelineMetrics(builds []*Build, releases []*Release, since, until time.Time) PipelineMetrics {
+
+	metrics := PipelineMetrics{
+		Since:   since,
+		Until:   until,
+		Targets: map[string]*DeploymentMetrics{},
+	}
+
+	succeeded := 0
+	finished := 0
+	for _, b := range filterBuildsByPeriod(builds, since, until) {
+		switch b.BuildStatus {
+		case StatusSucceeded:
+			succeeded++
+			finished++
+		case StatusFailed:
+			finished++
+		}
+	}
+	if finished > 0 {
+		metrics.BuildSuccessRate = getRate(succeeded, finished)
+	}
+	metrics.Flakiness = ComputeFlakiness(filterBuildsByPeriod(builds, since, until))
+
+	releasesPerTarget := map[string][]*Release{}
+	for _, r := range releases {
+		if r != nil {
+			releasesPerTarget[r.Name] = append(releasesPerTarget[r.Name], r)
+		}
+	}
+	for target, targetReleases := range releasesPerTarget {
+		targetMetrics := ComputeDeploymentMetrics(builds, targetReleases, since, until)
+		if targetMetrics.Deployments > 0 || targetMetrics.ChangeFailureRate != nil {
+			metrics.Targets[target] = &targetMetrics
+		}
+	}
+
+	return metrics
+}
+
+// ComputeDeploymentMetrics returns the DORA metrics for the releases of a single target inserted in the period
+func ComputeDeploymentMetrics(builds []*Build, releases []*Release, since, until time.Time) DeploymentMetrics {
+
+	metrics := DeploymentMetrics{
+		Since: since,
+		Until: until,
+	}
+
+	buildInsertedAtPerVersion := map[string]time.Time{}
+	for _, b := range builds {
+		if b == nil || b.BuildVersion == "" {
+			continue
+		}
+		if insertedAt, ok := buildInsertedAtPerVersion[b.BuildVersion]; !ok || b.InsertedAt.Before(insertedAt) {
+			buildInsertedAtPerVersion[b.BuildVersion] = b.InsertedAt
+		}
+	}
+
+	periodReleases := []*Release{}
+	for _, r := range releases {
+		if r != nil && r.InsertedAt != nil && !r.InsertedAt.Before(since) && r.InsertedAt.Before(until) {
+			periodReleases = append(periodReleases, r)
+		}
+	}
+	sort.SliceStable(periodReleases, func(i, j int) bool {
+		return periodReleases[i].InsertedAt.Before(*periodReleases[j].InsertedAt)
+	})
+
+	failed := 0
+	leadTimes := []time.Duration{}
+	restoreTimes := []time.Duration{}
+	var failedAt *time.Time
+	for _, r := range periodReleases {
+		finishedAt := getReleaseFinishedAt(r)
+
+		switch r.ReleaseStatus {
+		case StatusSucceeded:
+			metrics.Deployments++
+			// lead time runs from insertion of the build with the released version until the release finished
+			if buildInsertedAt, ok := buildInsertedAtPerVersion[r.ReleaseVersion]; ok && finishedAt != nil && !finishedAt.Before(buildInsertedAt) {
+				leadTimes = append(leadTimes, finishedAt.Sub(buildInsertedAt))
+			}
+			if failedAt != nil && finishedAt != nil {
+				restoreTimes = append(restoreTimes, finishedAt.Sub(*failedAt))
+			}
+			failedAt = nil
+
+		case StatusFailed:
+			failed++
+			if failedAt == nil {
+				failedAt = finishedAt
+			}
+		}
+	}
+
+	if days := until.Sub(since).Hours() / 24; days > 0 {
+		metrics.DeploymentsPerDay = float64(metrics.Deployments) / days
+	}
+	if len(leadTimes) > 0 {
+		medianLeadTime := getMedianDuration(leadTimes)
+		metrics.MedianLeadTime = &medianLeadTime
+	}
+	if metrics.Deployments+failed > 0 {
+		metrics.ChangeFailureRate = getRate(failed, metrics.Deployments+failed)
+	}
+	if len(restoreTimes) > 0 {
+		total := time.Duration(0)
+		for _, d := range restoreTimes {
+			total += d
+		}
+		meanTimeToRestore := total / time.Duration(len(restoreTimes))
+		metrics.MeanTimeToRestore = &meanTimeToRestore
+	}
+
+	return metrics
+}
+
+// ComputeFlakiness returns the fraction of revisions with a failed build that succeeded when built again, or nil without finished builds
+func ComputeFlakiness(builds []*Build) *float64 {
+
+	sortedBuilds := []*Build{}
+	for _, b := range builds {
+		if b != nil && (b.BuildStatus == StatusSucceeded || b.BuildStatus == StatusFailed) {
+			sortedBuilds = append(sortedBuilds, b)
+		}
+	}
+	sort.SliceStable(sortedBuilds, func(i, j int) bool {
+		return sortedBuilds[i].InsertedAt.Before(sortedBuilds[j].InsertedAt)
+	})
+
+	revisions := map[string]bool{}
+	failedRevisions := map[string]bool{}
+	flakyRevisions := map[string]bool{}
+	for _, b := range sortedBuilds {
+		revision := b.GetFullRepoPath() + "@" + b.RepoRevision
+		revisions[revision] = true
+		if b.BuildStatus == StatusFailed {
+			failedRevisions[revision] = true
+		} else if failedRevisions[revision] {
+			flakyRevisions[revision] = true
+		}
+	}
+
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	return getRate(len(flakyRevisions), len(revisions))
+}
+
+func filterBuildsByPeriod(builds []*Build, since, until time.Time) []*Build {
+	filteredBuilds := []*Build{}
+	for _, b := range builds {
+		if b != nil && !b.InsertedAt.Before(since) && b.InsertedAt.Before(until) {
+			filteredBuilds = append(filteredBuilds, b)
+		}
+	}
+
+	return filteredBuilds
+}
+
+// getReleaseFinishedAt returns the time a release finished from its start or insert time and duration, falling back to the update time
+func getReleaseFinishedAt(release *Release) *time.Time {
+	startedAt := release.StartedAt
+	if startedAt == nil {
+		startedAt = release.InsertedAt
+	}
+	if startedAt != nil && release.Duration != nil {
+		finishedAt := startedAt.Add(*release.Duration)
+		return &finishedAt
+	}
+
+	return release.UpdatedAt
+}
+
+func getMedianDuration(durations []time.Duration) time.Duration {
+	sortedDurations := append([]time.Duration{}, durations...)
+	sort.Slice(sortedDurations, func(i, j int) bool {
+		return sortedDurations[i] < sortedDurations[j]
+	})
+
+	middle := len(sortedDurations) / 2
+	if len(sortedDurations)%2 == 0 {
+		return (sortedDurations[middle-1] + sortedDurations[middle]) / 2
+	}
+
+	return sortedDurations[middle]
+}
+
+func getRate(count, total int) *float64 {
+	rate := float64(count) / float64(total)
+	return &rate
+}
@@ -0,0 +1,140 @@
+package contracts
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeDeploymentMetrics(t *testing.T) {
+	t.Run("ReturnsFrequencyLeadTimeChangeFailureRateAndTimeToRestore", func(t *testing.T) {
+
+		since := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+		until := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+		builds := []*Build{
+			{BuildVersion: "1.0.1", InsertedAt: time.Date(2021, 4, 1, 8, 0, 0, 0, time.UTC)},
+			{BuildVersion: "1.0.2", InsertedAt: time.Date(2021, 4, 2, 8, 0, 0, 0, time.UTC)},
+			{BuildVersion: "1.0.3", InsertedAt: time.Date(2021, 4, 3, 8, 0, 0, 0, time.UTC)},
+		}
+		duration := 10 * time.Minute
+		insertedAt1 := time.Date(2021, 4, 1, 9, 0, 0, 0, time.UTC)
+		insertedAt2 := time.Date(2021, 4, 2, 10, 0, 0, 0, time.UTC)
+		insertedAt3 := time.Date(2021, 4, 3, 10, 0, 0, 0, time.UTC)
+		insertedAt4 := time.Date(2021, 4, 4, 10, 0, 0, 0, time.UTC)
+		insertedAt5 := time.Date(2021, 3, 30, 10, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "1", Name: "production", ReleaseVersion: "1.0.1", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt1, Duration: &duration},
+			{ID: "2", Name: "production", ReleaseVersion: "1.0.2", ReleaseStatus: StatusFailed, InsertedAt: &insertedAt2, Duration: &duration},
+			{ID: "3", Name: "production", ReleaseVersion: "1.0.3", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt3, Duration: &duration},
+			{ID: "4", Name: "production", ReleaseVersion: "1.0.3", ReleaseStatus: StatusCanceled, InsertedAt: &insertedAt4, Duration: &duration},
+			{ID: "5", Name: "production", ReleaseVersion: "1.0.0", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt5, Duration: &duration},
+		}
+
+		// act
+		metrics := ComputeDeploymentMetrics(builds, releases, since, until)
+
+		assert.Equal(t, 2, metrics.Deployments)
+		assert.Equal(t, 0.2, metrics.DeploymentsPerDay)
+		if assert.NotNil(t, metrics.MedianLeadTime) {
+			assert.Equal(t, 100*time.Minute, *metrics.MedianLeadTime)
+		}
+		if assert.NotNil(t, metrics.ChangeFailureRate) {
+			assert.InDelta(t, 1.0/3.0, *metrics.ChangeFailureRate, 0.0001)
+		}
+		if assert.NotNil(t, metrics.MeanTimeToRestore) {
+			assert.Equal(t, 24*time.Hour, *metrics.MeanTimeToRestore)
+		}
+	})
+
+	t.Run("ReturnsNoRatesWithoutFinishedReleases", func(t *testing.T) {
+
+		since := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+		until := time.Date(2021, 4, 11, 0, 0, 0, 0, time.UTC)
+
+		// act
+		metrics := ComputeDeploymentMetrics(nil, nil, since, until)
+
+		assert.Equal(t, 0, metrics.Deployments)
+		assert.Nil(t, metrics.MedianLeadTime)
+		assert.Nil(t, metrics.ChangeFailureRate)
+		assert.Nil(t, metrics.MeanTimeToRestore)
+	})
+}
+
+func TestComputeFlakiness(t *testing.T) {
+	t.Run("ReturnsFractionOfRevisionsThatFailedAndThenSucceeded", func(t *testing.T) {
+
+		builds := []*Build{
+			{RepoRevision: "a", BuildStatus: StatusFailed, InsertedAt: time.Date(2021, 4, 1, 8, 0, 0, 0, time.UTC)},
+			{RepoRevision: "a", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 1, 9, 0, 0, 0, time.UTC)},
+			{RepoRevision: "b", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 1, 10, 0, 0, 0, time.UTC)},
+			{RepoRevision: "b", BuildStatus: StatusFailed, InsertedAt: time.Date(2021, 4, 1, 11, 0, 0, 0, time.UTC)},
+			{RepoRevision: "c", BuildStatus: StatusFailed, InsertedAt: time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)},
+			{RepoRevision: "d", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 1, 13, 0, 0, 0, time.UTC)},
+			{RepoRevision: "e", BuildStatus: StatusRunning, InsertedAt: time.Date(2021, 4, 1, 14, 0, 0, 0, time.UTC)},
+		}
+
+		// act
+		flakiness := ComputeFlakiness(builds)
+
+		if assert.NotNil(t, flakiness) {
+			assert.Equal(t, 0.25, *flakiness)
+		}
+	})
+}
+
+func TestComputePipelineMetrics(t *testing.T) {
+	t.Run("ReturnsBuildSuccessRateAndMetricsPerTarget", func(t *testing.T) {
+
+		since := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+		until := time.Date(2021, 4, 3, 0, 0, 0, 0, time.UTC)
+		builds := []*Build{
+			{RepoRevision: "a", BuildVersion: "1.0.1", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 1, 8, 0, 0, 0, time.UTC)},
+			{RepoRevision: "b", BuildVersion: "1.0.2", BuildStatus: StatusFailed, InsertedAt: time.Date(2021, 4, 1, 9, 0, 0, 0, time.UTC)},
+			{RepoRevision: "c", BuildVersion: "1.0.3", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 1, 10, 0, 0, 0, time.UTC)},
+			{RepoRevision: "d", BuildVersion: "1.0.4", BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 1, 11, 0, 0, 0, time.UTC)},
+		}
+		duration := 10 * time.Minute
+		insertedAt1 := time.Date(2021, 4, 1, 8, 30, 0, 0, time.UTC)
+		insertedAt2 := time.Date(2021, 4, 1, 10, 30, 0, 0, time.UTC)
+		insertedAt3 := time.Date(2021, 4, 2, 10, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "1", Name: "staging", ReleaseVersion: "1.0.1", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt1, Duration: &duration},
+			{ID: "2", Name: "staging", ReleaseVersion: "1.0.3", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt2, Duration: &duration},
+			{ID: "3", Name: "production", ReleaseVersion: "1.0.3", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt3, Duration: &duration},
+		}
+
+		// act
+		metrics := ComputePipelineMetrics(builds, releases, since, until)
+
+		if assert.NotNil(t, metrics.BuildSuccessRate) {
+			assert.Equal(t, 0.75, *metrics.BuildSuccessRate)
+		}
+		if assert.NotNil(t, metrics.Flakiness) {
+			assert.Equal(t, 0.0, *metrics.Flakiness)
+		}
+		if !assert.Equal(t, 2, len(metrics.Targets)) {
+			return
+		}
+		assert.Equal(t, 1.0, metrics.Targets["staging"].DeploymentsPerDay)
+		assert.Equal(t, 0.5, metrics.Targets["production"].DeploymentsPerDay)
+		assert.Equal(t, 24*time.Hour+10*time.Minute, *metrics.Targets["production"].MedianLeadTime)
+	})
+
+	t.Run("CanBeCarriedInPipelineExtraInfo", func(t *testing.T) {
+
+		since := time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)
+		until := time.Date(2021, 4, 3, 0, 0, 0, 0, time.UTC)
+		builds := []*Build{{BuildStatus: StatusSucceeded, InsertedAt: time.Date(2021, 4, 1, 8, 0, 0, 0, time.UTC)}}
+		metrics := ComputePipelineMetrics(builds, nil, since, until)
+		pipeline := Pipeline{ExtraInfo: &PipelineExtraInfo{Metrics: &metrics}}
+
+		// act
+		bytes, err := json.Marshal(pipeline.ExtraInfo)
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"medianPendingDuration":0,"medianDuration":0,"metrics":{"since":"2021-04-01T00:00:00Z","until":"2021-04-03T00:00:00Z","buildSuccessRate":1,"flakiness":0}}`, string(bytes))
+	})
+}
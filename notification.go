@@ -36,6 +36,19 @@ type Notification struct {
 	Type    NotificationType  `json:"type,omitempty"`
 	Level   NotificationLevel `json:"level,omitempty"`
 	Message string            `json:"message,omitempty"`
+
+	// optional structured payload for notifications of type vulnerability
+	Vulnerability *VulnerabilityDetail `json:"vulnerability,omitempty"`
+}
+
+// VulnerabilityDetail describes a single vulnerability in a package found by a scanner
+type VulnerabilityDetail struct {
+	ID               string   `json:"id,omitempty"`
+	Package          string   `json:"package,omitempty"`
+	InstalledVersion string   `json:"installedVersion,omitempty"`
+	FixedVersion     string   `json:"fixedVersion,omitempty"`
+	CVSSScore        float64  `json:"cvssScore,omitempty"`
+	Links            []string `json:"links,omitempty"`
 }
 
 type NotificationRecord struct {
@@ -0,0 +1,276 @@
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type NotificationChannelType string
+
+const (
+	NotificationChannelTypeUnknown      NotificationChannelType = ""
+	NotificationChannelTypeSlackWebhook NotificationChannelType = "slack-webhook"
+	NotificationChannelTypeEmail        NotificationChannelType = "email"
+	NotificationChannelTypeWebhook      NotificationChannelType = "webhook"
+)
+
+// NotificationChannel is a destination for notifications
+type NotificationChannel struct {
+	Type       NotificationChannelType `yaml:"type" json:"type"`
+	Credential string                  `yaml:"credential,omitempty" json:"credential,omitempty"`
+	Channel    string                  `yaml:"channel,omitempty" json:"channel,omitempty"`
+	Recipients []string                `yaml:"recipients,omitempty" json:"recipients,omitempty"`
+	URL        string                  `yaml:"url,omitempty" json:"url,omitempty"`
+}
+
+// NotificationRoutingRule routes matching notifications to one or more channels
+type NotificationRoutingRule struct {
+	Name          string                 `yaml:"name" json:"name"`
+	Types         []NotificationType     `yaml:"types,omitempty" json:"types,omitempty"`
+	MinimumLevel  NotificationLevel      `yaml:"minimumLevel,omitempty" json:"minimumLevel,omitempty"`
+	LinkTypes     []NotificationLinkType `yaml:"linkTypes,omitempty" json:"linkTypes,omitempty"`
+	Pipelines     string                 `yaml:"pipelines,omitempty" json:"pipelines,omitempty"`
+	Groups        []string               `yaml:"groups,omitempty" json:"groups,omitempty"`
+	Organizations []string               `yaml:"organizations,omitempty" json:"organizations,omitempty"`
+	Channels      []NotificationChannel  `yaml:"channels" json:"channels"`
+
+	pipelinesRegex *regexp.Regexp
+}
+
+// NotificationDelivery is a single delivery of the notifications matched by a rule to one of its channels
+type NotificationDelivery struct {
+	Rule          string              `json:"rule"`
+	Channel       NotificationChannel `json:"channel"`
+	Record        NotificationRecord  `json:"record"`
+	Notifications []Notification      `json:"notifications"`
+}
+
+// NotificationWebhookPayload is the body posted to channels of type webhook
+type NotificationWebhookPayload struct {
+	Rule            string               `json:"rule"`
+	LinkType        NotificationLinkType `json:"linkType,omitempty"`
+	LinkID          string               `json:"linkID,omitempty"`
+	PipelineDetail  *PipelineLinkDetail  `json:"pipelineDetail,omitempty"`
+	ContainerDetail *ContainerLinkDetail `json:"containerDetail,omitempty"`
+	Source          string               `json:"source,omitempty"`
+	Summary         NotificationSummary  `json:"summary"`
+	Notifications   []Notification       `json:"notifications"`
+	SentAt          time.Time            `json:"sentAt"`
+}
+
+// Validate checks whether the rule has a name and valid channels and pipelines pattern
+func (r *NotificationRoutingRule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("Notification routing rule needs a name")
+	}
+	if r.Pipelines != "" {
+		pipelinesRegex, err := compilePipelinesPattern(r.Pipelines)
+		if err != nil {
+			return fmt.Errorf("Notification routing rule %v has invalid pipelines pattern: %w", r.Name, err)
+		}
+		r.pipelinesRegex = pipelinesRegex
+	}
+	if len(r.Channels) == 0 {
+		return fmt.Errorf("Notification routing rule %v needs at least one channel", r.Name)
+	}
+	for _, c := range r.Channels {
+		switch c.Type {
+		case NotificationChannelTypeSlackWebhook:
+			if c.Credential == "" {
+				return fmt.Errorf("Notification routing rule %v has slack-webhook channel without credential", r.Name)
+			}
+		case NotificationChannelTypeEmail:
+			if len(c.Recipients) == 0 {
+				return fmt.Errorf("Notification routing rule %v has email channel without recipients", r.Name)
+			}
+		case NotificationChannelTypeWebhook:
+			if c.URL == "" {
+				return fmt.Errorf("Notification routing rule %v has webhook channel without url", r.Name)
+			}
+		default:
+			return fmt.Errorf("Notification routing rule %v has unsupported channel type %v", r.Name, c.Type)
+		}
+	}
+
+	return nil
+}
+
+// MatchesRecord returns true if the record's link, pipeline, groups and organizations match the rule
+func (r *NotificationRoutingRule) MatchesRecord(record NotificationRecord) bool {
+	if len(r.LinkTypes) > 0 {
+		isMatch := false
+		for _, lt := range r.LinkTypes {
+			if lt == record.LinkType {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	if r.Pipelines != "" {
+		if record.LinkType != NotificationLinkTypePipeline {
+			return false
+		}
+		pipelinesRegex := r.pipelinesRegex
+		if pipelinesRegex == nil {
+			// the rule hasn't been validated
+			var err error
+			if pipelinesRegex, err = compilePipelinesPattern(r.Pipelines); err != nil {
+				return false
+			}
+		}
+		if !pipelinesRegex.MatchString(record.LinkID) {
+			return false
+		}
+	}
+
+	if len(r.Groups) > 0 {
+		isMatch := false
+		for _, g := range record.Groups {
+			if g != nil && containsString(r.Groups, g.Name) {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	if len(r.Organizations) > 0 {
+		isMatch := false
+		for _, o := range record.Organizations {
+			if o != nil && containsString(r.Organizations, o.Name) {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchesNotification returns true if the notification's type and level match the rule
+func (r *NotificationRoutingRule) MatchesNotification(notification Notification) bool {
+	if len(r.Types) > 0 {
+		isMatch := false
+		for _, t := range r.Types {
+			if t == notification.Type {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			return false
+		}
+	}
+
+	return notification.Level.IsAtLeast(r.MinimumLevel)
+}
+
+// EvaluateNotificationRoutingRules returns a delivery for each channel of each rule that matches at least one notification in the record
+func EvaluateNotificationRoutingRules(rules []*NotificationRoutingRule, record NotificationRecord) []NotificationDelivery {
+
+	deliveries := []NotificationDelivery{}
+
+	for _, r := range rules {
+		if r == nil || !r.MatchesRecord(record) {
+			continue
+		}
+
+		notifications := []Notification{}
+		for _, n := range record.Notifications {
+			if r.MatchesNotification(n) {
+				notifications = append(notifications, n)
+			}
+		}
+		if len(notifications) == 0 {
+			continue
+		}
+
+		for _, c := range r.Channels {
+			deliveries = append(deliveries, NotificationDelivery{
+				Rule:          r.Name,
+				Channel:       c,
+				Record:        record,
+				Notifications: notifications,
+			})
+		}
+	}
+
+	return deliveries
+}
+
+// GetWebhookPayload returns the body to post for the delivery
+func (d *NotificationDelivery) GetWebhookPayload(sentAt time.Time) NotificationWebhookPayload {
+	return NotificationWebhookPayload{
+		Rule:            d.Rule,
+		LinkType:        d.Record.LinkType,
+		LinkID:          d.Record.LinkID,
+		PipelineDetail:  d.Record.PipelineDetail,
+		ContainerDetail: d.Record.ContainerDetail,
+		Source:          d.Record.Source,
+		Summary:         GetNotificationSummary([]*NotificationRecord{{Notifications: d.Notifications}}),
+		Notifications:   d.Notifications,
+		SentAt:          sentAt,
+	}
+}
+
+// DeliverWebhook posts the webhook payload to the channel url with the http client, or the default client if it's nil
+func (d *NotificationDelivery) DeliverWebhook(ctx context.Context, httpClient *http.Client, sentAt time.Time) error {
+	if d.Channel.Type != NotificationChannelTypeWebhook {
+		return fmt.Errorf("Notification delivery for rule %v has channel type %v instead of webhook", d.Rule, d.Channel.Type)
+	}
+
+	body, err := json.Marshal(d.GetWebhookPayload(sentAt))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Channel.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("Notification delivery for rule %v to %v failed with status code %v", d.Rule, d.Channel.URL, response.StatusCode)
+	}
+
+	return nil
+}
+
+func compilePipelinesPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(fmt.Sprintf("^(%v)$", strings.TrimSpace(pattern)))
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
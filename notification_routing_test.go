@@ -0,0 +1,249 @@
+package contracts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestEvaluateNotificationRoutingRules(t *testing.T) {
+	t.Run("ReturnsDeliveryPerChannelWithMatchingNotifications", func(t *testing.T) {
+
+		rules := []*NotificationRoutingRule{
+			{
+				Name:         "critical-vulnerabilities",
+				Types:        []NotificationType{NotificationTypeVulnerability},
+				MinimumLevel: NotificationLevelHigh,
+				Pipelines:    "github.com/estafette/.+",
+				Channels: []NotificationChannel{
+					{Type: NotificationChannelTypeSlackWebhook, Credential: "slack-webhook-estafette", Channel: "#estafette"},
+					{Type: NotificationChannelTypeEmail, Recipients: []string{"security@estafette.io"}},
+				},
+			},
+		}
+		record := NotificationRecord{
+			LinkType: NotificationLinkTypePipeline,
+			LinkID:   "github.com/estafette/estafette-ci-api",
+			Notifications: []Notification{
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical, Message: "CVE-2021-0001"},
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelLow, Message: "CVE-2021-0002"},
+				{Type: NotificationTypeWarning, Level: NotificationLevelCritical, Message: "use a specific tag"},
+			},
+		}
+
+		// act
+		deliveries := EvaluateNotificationRoutingRules(rules, record)
+
+		if !assert.Equal(t, 2, len(deliveries)) {
+			return
+		}
+		assert.Equal(t, "critical-vulnerabilities", deliveries[0].Rule)
+		assert.Equal(t, NotificationChannelTypeSlackWebhook, deliveries[0].Channel.Type)
+		assert.Equal(t, NotificationChannelTypeEmail, deliveries[1].Channel.Type)
+		assert.Equal(t, []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical, Message: "CVE-2021-0001"}}, deliveries[0].Notifications)
+	})
+
+	t.Run("ReturnsNoDeliveriesIfPipelineDoesNotMatch", func(t *testing.T) {
+
+		rules := []*NotificationRoutingRule{
+			{
+				Name:      "estafette",
+				Pipelines: "github.com/estafette/.+",
+				Channels:  []NotificationChannel{{Type: NotificationChannelTypeWebhook, URL: "https://estafette.io"}},
+			},
+		}
+		record := NotificationRecord{
+			LinkType:      NotificationLinkTypePipeline,
+			LinkID:        "github.com/other/repo",
+			Notifications: []Notification{{Type: NotificationTypeWarning, Level: NotificationLevelLow}},
+		}
+
+		// act
+		deliveries := EvaluateNotificationRoutingRules(rules, record)
+
+		assert.Equal(t, 0, len(deliveries))
+	})
+
+	t.Run("ReturnsNoDeliveriesIfGroupDoesNotMatch", func(t *testing.T) {
+
+		rules := []*NotificationRoutingRule{
+			{
+				Name:     "team-payments",
+				Groups:   []string{"payments"},
+				Channels: []NotificationChannel{{Type: NotificationChannelTypeWebhook, URL: "https://estafette.io"}},
+			},
+		}
+		record := NotificationRecord{
+			LinkType:      NotificationLinkTypeContainer,
+			LinkID:        "extensions/docker",
+			Groups:        []*Group{{Name: "platform"}},
+			Notifications: []Notification{{Type: NotificationTypeWarning, Level: NotificationLevelLow}},
+		}
+
+		// act
+		deliveries := EvaluateNotificationRoutingRules(rules, record)
+
+		assert.Equal(t, 0, len(deliveries))
+	})
+
+	t.Run("ReturnsNoDeliveriesIfNoNotificationHasMinimumLevel", func(t *testing.T) {
+
+		rules := []*NotificationRoutingRule{
+			{
+				Name:         "critical",
+				MinimumLevel: NotificationLevelCritical,
+				Channels:     []NotificationChannel{{Type: NotificationChannelTypeWebhook, URL: "https://estafette.io"}},
+			},
+		}
+		record := NotificationRecord{
+			Notifications: []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh}},
+		}
+
+		// act
+		deliveries := EvaluateNotificationRoutingRules(rules, record)
+
+		assert.Equal(t, 0, len(deliveries))
+	})
+}
+
+func TestNotificationRoutingRuleValidate(t *testing.T) {
+	t.Run("ReturnsNilForValidRuleFromYaml", func(t *testing.T) {
+
+		var rule NotificationRoutingRule
+		err := yaml.Unmarshal([]byte(`
+name: critical-vulnerabilities
+types:
+- vulnerability
+minimumLevel: critical
+linkTypes:
+- container
+channels:
+- type: slack-webhook
+  credential: slack-webhook-estafette
+  channel: '#estafette'
+- type: webhook
+  url: https://estafette.io/hooks/notifications
+`), &rule)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		err = rule.Validate()
+
+		assert.Nil(t, err)
+		assert.Equal(t, NotificationLevelCritical, rule.MinimumLevel)
+		assert.Equal(t, 2, len(rule.Channels))
+	})
+
+	t.Run("ReturnsErrorForEmailChannelWithoutRecipients", func(t *testing.T) {
+
+		rule := NotificationRoutingRule{
+			Name:     "email",
+			Channels: []NotificationChannel{{Type: NotificationChannelTypeEmail}},
+		}
+
+		// act
+		err := rule.Validate()
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForInvalidPipelinesPattern", func(t *testing.T) {
+
+		rule := NotificationRoutingRule{
+			Name:      "webhook",
+			Pipelines: "github.com/estafette/(",
+			Channels:  []NotificationChannel{{Type: NotificationChannelTypeWebhook, URL: "https://example.com"}},
+		}
+
+		// act
+		err := rule.Validate()
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestNotificationDeliveryDeliverWebhook(t *testing.T) {
+	t.Run("PostsPayloadToWebhookURL", func(t *testing.T) {
+
+		var payload NotificationWebhookPayload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&payload))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		sentAt := time.Date(2021, 4, 17, 8, 3, 0, 0, time.UTC)
+		rules := []*NotificationRoutingRule{
+			{
+				Name:     "all",
+				Channels: []NotificationChannel{{Type: NotificationChannelTypeWebhook, URL: server.URL}},
+			},
+		}
+		record := NotificationRecord{
+			LinkType:        NotificationLinkTypeContainer,
+			LinkID:          "extensions/docker",
+			ContainerDetail: &ContainerLinkDetail{Tag: "1.2.3"},
+			Notifications:   []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical, Message: "CVE-2021-0001"}},
+		}
+		deliveries := EvaluateNotificationRoutingRules(rules, record)
+		if !assert.Equal(t, 1, len(deliveries)) {
+			return
+		}
+
+		// act
+		err := deliveries[0].DeliverWebhook(context.Background(), server.Client(), sentAt)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "all", payload.Rule)
+		assert.Equal(t, "extensions/docker", payload.LinkID)
+		assert.Equal(t, "1.2.3", payload.ContainerDetail.Tag)
+		assert.Equal(t, NotificationLevelCritical, payload.Summary.HighestLevel)
+		assert.Equal(t, 1, len(payload.Notifications))
+		assert.Equal(t, sentAt, payload.SentAt)
+	})
+
+	t.Run("ReturnsErrorIfWebhookRespondsWithErrorStatus", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		delivery := NotificationDelivery{
+			Rule:    "all",
+			Channel: NotificationChannel{Type: NotificationChannelTypeWebhook, URL: server.URL},
+		}
+
+		// act
+		err := delivery.DeliverWebhook(context.Background(), server.Client(), time.Now())
+
+		assert.NotNil(t, err)
+	})
+	t.Run("UsesDefaultClientIfHttpClientIsNil", func(t *testing.T) {
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		delivery := NotificationDelivery{
+			Rule:    "all",
+			Channel: NotificationChannel{Type: NotificationChannelTypeWebhook, URL: server.URL},
+		}
+
+		// act
+		err := delivery.DeliverWebhook(context.Background(), nil, time.Now())
+
+		assert.Nil(t, err)
+	})
+}
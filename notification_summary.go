@@ -0,0 +1,172 @@
+package contracts
+
+import (
+	"fmt"
+)
+
+// NotificationSummary contains the highest severity and counts per level and type for one or more notification records
+type NotificationSummary struct {
+	HighestLevel  NotificationLevel         `json:"highestLevel,omitempty"`
+	Total         int                       `json:"total"`
+	CountPerLevel map[NotificationLevel]int `json:"countPerLevel,omitempty"`
+	CountPerType  map[NotificationType]int  `json:"countPerType,omitempty"`
+}
+
+// NotificationDiff contains the notifications that are new, resolved or unchanged between two records for the same link
+type NotificationDiff struct {
+	New       []Notification `json:"new,omitempty"`
+	Resolved  []Notification `json:"resolved,omitempty"`
+	Unchanged []Notification `json:"unchanged,omitempty"`
+}
+
+// Severity returns a number that increases with the severity of the level, so levels can be compared
+func (l NotificationLevel) Severity() int {
+	switch l {
+	case NotificationLevelCritical:
+		return 4
+	case NotificationLevelHigh:
+		return 3
+	case NotificationLevelMedium:
+		return 2
+	case NotificationLevelLow:
+		return 1
+	}
+
+	return 0
+}
+
+// IsAtLeast returns true if the level is as severe or more severe than the parameterized level
+func (l NotificationLevel) IsAtLeast(level NotificationLevel) bool {
+	return l.Severity() >= level.Severity()
+}
+
+// GetHighestLevel returns the most severe level of all notifications in the record
+func (nr *NotificationRecord) GetHighestLevel() NotificationLevel {
+	return GetHighestNotificationLevel(nr.Notifications)
+}
+
+// GetHighestNotificationLevel returns the most severe level of all notifications
+func GetHighestNotificationLevel(notifications []Notification) NotificationLevel {
+	highestLevel := NotificationLevelUnknown
+	for _, n := range notifications {
+		if n.Level.Severity() > highestLevel.Severity() {
+			highestLevel = n.Level
+		}
+	}
+
+	return highestLevel
+}
+
+// GetSummary returns the highest severity and counts per level and type for the record
+func (nr *NotificationRecord) GetSummary() NotificationSummary {
+	return GetNotificationSummary([]*NotificationRecord{nr})
+}
+
+// GetNotificationSummary returns the highest severity and counts per level and type for all records
+func GetNotificationSummary(records []*NotificationRecord) NotificationSummary {
+	summary := NotificationSummary{
+		CountPerLevel: map[NotificationLevel]int{},
+		CountPerType:  map[NotificationType]int{},
+	}
+
+	for _, nr := range records {
+		if nr == nil {
+			continue
+		}
+		for _, n := range nr.Notifications {
+			summary.Total++
+			summary.CountPerLevel[n.Level]++
+			summary.CountPerType[n.Type]++
+			if n.Level.Severity() > summary.HighestLevel.Severity() {
+				summary.HighestLevel = n.Level
+			}
+		}
+	}
+
+	return summary
+}
+
+// FilterNotifications returns the notifications of a type, or of any type if unknown, with at least the minimum level
+func FilterNotifications(notifications []Notification, notificationType NotificationType, minimumLevel NotificationLevel) []Notification {
+	filteredNotifications := []Notification{}
+	for _, n := range notifications {
+		if notificationType != NotificationTypeUnknown && n.Type != notificationType {
+			continue
+		}
+		if !n.Level.IsAtLeast(minimumLevel) {
+			continue
+		}
+		filteredNotifications = append(filteredNotifications, n)
+	}
+
+	return filteredNotifications
+}
+
+// DeduplicateNotifications removes identical notifications from the record, keeping the first occurrence
+func (nr *NotificationRecord) DeduplicateNotifications() {
+	nr.Notifications = DeduplicateNotifications(nr.Notifications)
+}
+
+// DeduplicateNotifications returns the notifications without duplicates, keeping the first occurrence
+func DeduplicateNotifications(notifications []Notification) []Notification {
+	seen := map[string]bool{}
+	deduplicatedNotifications := []Notification{}
+	for _, n := range notifications {
+		key := n.getKey()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduplicatedNotifications = append(deduplicatedNotifications, n)
+	}
+
+	return deduplicatedNotifications
+}
+
+// DiffNotificationRecords returns which notifications are new in the current record, which got resolved since the previous record and which are unchanged
+func DiffNotificationRecords(previous, current *NotificationRecord) (diff NotificationDiff, err error) {
+	if previous != nil && current != nil && (previous.LinkType != current.LinkType || previous.LinkID != current.LinkID) {
+		return diff, fmt.Errorf("NotificationRecord for %v %v can't be compared to NotificationRecord for %v %v", current.LinkType, current.LinkID, previous.LinkType, previous.LinkID)
+	}
+
+	var previousNotifications, currentNotifications []Notification
+	if previous != nil {
+		previousNotifications = DeduplicateNotifications(previous.Notifications)
+	}
+	if current != nil {
+		currentNotifications = DeduplicateNotifications(current.Notifications)
+	}
+
+	previousKeys := map[string]bool{}
+	for _, n := range previousNotifications {
+		previousKeys[n.getKey()] = true
+	}
+	currentKeys := map[string]bool{}
+	for _, n := range currentNotifications {
+		currentKeys[n.getKey()] = true
+	}
+
+	for _, n := range currentNotifications {
+		if previousKeys[n.getKey()] {
+			diff.Unchanged = append(diff.Unchanged, n)
+		} else {
+			diff.New = append(diff.New, n)
+		}
+	}
+	for _, n := range previousNotifications {
+		if !currentKeys[n.getKey()] {
+			diff.Resolved = append(diff.Resolved, n)
+		}
+	}
+
+	return diff, nil
+}
+
+// getKey returns the identity of a notification used for deduplication and diffing
+func (n Notification) getKey() string {
+	if n.Vulnerability != nil && n.Vulnerability.ID != "" {
+		return fmt.Sprintf("%v|%v|%v|%v", n.Type, n.Vulnerability.ID, n.Vulnerability.Package, n.Vulnerability.InstalledVersion)
+	}
+
+	return fmt.Sprintf("%v|%v|%v", n.Type, n.Level, n.Message)
+}
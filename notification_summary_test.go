@@ -0,0 +1,155 @@
+package contracts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNotificationSummary(t *testing.T) {
+	t.Run("ReturnsHighestLevelAndCountsAcrossRecords", func(t *testing.T) {
+
+		records := []*NotificationRecord{
+			{
+				LinkType: NotificationLinkTypeContainer,
+				LinkID:   "extensions/docker",
+				Notifications: []Notification{
+					{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"},
+					{Type: NotificationTypeVulnerability, Level: NotificationLevelLow, Message: "CVE-2021-0002"},
+				},
+			},
+			{
+				LinkType: NotificationLinkTypePipeline,
+				LinkID:   "github.com/estafette/estafette-ci-api",
+				Notifications: []Notification{
+					{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical, Message: "CVE-2021-0003"},
+					{Type: NotificationTypeWarning, Level: NotificationLevelMedium, Message: "use a specific tag"},
+				},
+			},
+		}
+
+		// act
+		summary := GetNotificationSummary(records)
+
+		assert.Equal(t, NotificationLevelCritical, summary.HighestLevel)
+		assert.Equal(t, 4, summary.Total)
+		assert.Equal(t, 1, summary.CountPerLevel[NotificationLevelCritical])
+		assert.Equal(t, 1, summary.CountPerLevel[NotificationLevelHigh])
+		assert.Equal(t, 1, summary.CountPerLevel[NotificationLevelMedium])
+		assert.Equal(t, 1, summary.CountPerLevel[NotificationLevelLow])
+		assert.Equal(t, 3, summary.CountPerType[NotificationTypeVulnerability])
+		assert.Equal(t, 1, summary.CountPerType[NotificationTypeWarning])
+	})
+
+	t.Run("ReturnsUnknownLevelForRecordWithoutNotifications", func(t *testing.T) {
+
+		record := NotificationRecord{}
+
+		// act
+		summary := record.GetSummary()
+
+		assert.Equal(t, NotificationLevelUnknown, summary.HighestLevel)
+		assert.Equal(t, 0, summary.Total)
+	})
+}
+
+func TestDeduplicateNotifications(t *testing.T) {
+	t.Run("RemovesIdenticalNotificationsKeepingOrder", func(t *testing.T) {
+
+		record := NotificationRecord{
+			Notifications: []Notification{
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"},
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelLow, Message: "CVE-2021-0002"},
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"},
+			},
+		}
+
+		// act
+		record.DeduplicateNotifications()
+
+		if !assert.Equal(t, 2, len(record.Notifications)) {
+			return
+		}
+		assert.Equal(t, "CVE-2021-0001", record.Notifications[0].Message)
+		assert.Equal(t, "CVE-2021-0002", record.Notifications[1].Message)
+	})
+}
+
+func TestDiffNotificationRecords(t *testing.T) {
+	t.Run("ReturnsNewResolvedAndUnchangedNotifications", func(t *testing.T) {
+
+		previous := &NotificationRecord{
+			LinkType: NotificationLinkTypeContainer,
+			LinkID:   "extensions/docker",
+			Notifications: []Notification{
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"},
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelLow, Message: "CVE-2021-0002"},
+			},
+		}
+		current := &NotificationRecord{
+			LinkType: NotificationLinkTypeContainer,
+			LinkID:   "extensions/docker",
+			Notifications: []Notification{
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"},
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical, Message: "CVE-2021-0003"},
+			},
+		}
+
+		// act
+		diff, err := DiffNotificationRecords(previous, current)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical, Message: "CVE-2021-0003"}}, diff.New)
+		assert.Equal(t, []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelLow, Message: "CVE-2021-0002"}}, diff.Resolved)
+		assert.Equal(t, []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"}}, diff.Unchanged)
+		assert.Equal(t, 1, len(FilterNotifications(diff.New, NotificationTypeVulnerability, NotificationLevelCritical)))
+	})
+
+	t.Run("ReturnsAllNotificationsAsNewIfThereIsNoPreviousRecord", func(t *testing.T) {
+
+		current := &NotificationRecord{
+			Notifications: []Notification{
+				{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"},
+			},
+		}
+
+		// act
+		diff, err := DiffNotificationRecords(nil, current)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(diff.New))
+		assert.Equal(t, 0, len(diff.Resolved))
+	})
+
+	t.Run("ReturnsErrorIfRecordsAreForDifferentLinks", func(t *testing.T) {
+
+		previous := &NotificationRecord{LinkType: NotificationLinkTypeContainer, LinkID: "extensions/docker"}
+		current := &NotificationRecord{LinkType: NotificationLinkTypeContainer, LinkID: "extensions/gke"}
+
+		// act
+		_, err := DiffNotificationRecords(previous, current)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestFilterNotifications(t *testing.T) {
+	t.Run("ReturnsNotificationsOfTypeWithAtLeastMinimumLevel", func(t *testing.T) {
+
+		notifications := []Notification{
+			{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh, Message: "CVE-2021-0001"},
+			{Type: NotificationTypeVulnerability, Level: NotificationLevelLow, Message: "CVE-2021-0002"},
+			{Type: NotificationTypeWarning, Level: NotificationLevelCritical, Message: "use a specific tag"},
+		}
+
+		// act
+		filteredNotifications := FilterNotifications(notifications, NotificationTypeVulnerability, NotificationLevelHigh)
+
+		if !assert.Equal(t, 1, len(filteredNotifications)) {
+			return
+		}
+		assert.Equal(t, "CVE-2021-0001", filteredNotifications[0].Message)
+	})
+}
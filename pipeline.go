@@ -46,6 +46,8 @@ func (pipeline *Pipeline) GetFullRepoPath() string {
 
 // PipelineExtraInfo contains extra information like aggregates over the last x builds
 type PipelineExtraInfo struct {
-	MedianPendingDuration time.Duration `json:"medianPendingDuration"`
-	MedianDuration        time.Duration `json:"medianDuration"`
+	MedianPendingDuration time.Duration    `json:"medianPendingDuration"`
+	MedianDuration        time.Duration    `json:"medianDuration"`
+	Metrics               *PipelineMetrics `json:"metrics,omitempty"`
+	DurationExtraInfo
 }
@@ -24,14 +24,17 @@ type Release struct {
 	Duration        *time.Duration            `json:"duration,omitempty"`
 	PendingDuration *time.Duration            `json:"pendingDuration,omitempty"`
 	ExtraInfo       *ReleaseExtraInfo         `json:"extraInfo,omitempty"`
+	Approvals       []*ReleaseApproval        `json:"approvals,omitempty"`
 	Groups          []*Group                  `json:"groups,omitempty"`
 	Organizations   []*Organization           `json:"organizations,omitempty"`
 }
 
 // ReleaseExtraInfo contains extra information like aggregates over the last x releases
 type ReleaseExtraInfo struct {
-	MedianPendingDuration time.Duration `json:"medianPendingDuration"`
-	MedianDuration        time.Duration `json:"medianDuration"`
+	MedianPendingDuration time.Duration      `json:"medianPendingDuration"`
+	MedianDuration        time.Duration      `json:"medianDuration"`
+	Metrics               *DeploymentMetrics `json:"metrics,omitempty"`
+	DurationExtraInfo
 }
 
 // GetFullRepoPath returns the full path of the release repository with source, owner and name
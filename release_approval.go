@@ -0,0 +1,349 @@
+package contracts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type ReleaseApprovalDecision string
+
+const (
+	ReleaseApprovalDecisionUnknown  ReleaseApprovalDecision = ""
+	ReleaseApprovalDecisionApproved ReleaseApprovalDecision = "approved"
+	ReleaseApprovalDecisionRejected ReleaseApprovalDecision = "rejected"
+)
+
+type ReleaseApprovalState string
+
+const (
+	ReleaseApprovalStateUnknown     ReleaseApprovalState = ""
+	ReleaseApprovalStateNotRequired ReleaseApprovalState = "not-required"
+	ReleaseApprovalStateAwaiting    ReleaseApprovalState = "awaiting"
+	ReleaseApprovalStateApproved    ReleaseApprovalState = "approved"
+	ReleaseApprovalStateRejected    ReleaseApprovalState = "rejected"
+)
+
+// ReleaseApprovalPolicy requires sign-off by other users before a release to one of its targets can start
+type ReleaseApprovalPolicy struct {
+	Name    string   `yaml:"name" json:"name"`
+	Targets []string `yaml:"targets" json:"targets"`
+	// Actions limits the policy to releases with one of these actions
+	Actions           []string `yaml:"actions,omitempty" json:"actions,omitempty"`
+	RequiredApprovals int      `yaml:"requiredApprovals,omitempty" json:"requiredApprovals,omitempty"`
+	// ApproverGroups and ApproverRoles limit who can approve or reject, by group name or id and by role
+	ApproverGroups []string `yaml:"approverGroups,omitempty" json:"approverGroups,omitempty"`
+	ApproverRoles  []string `yaml:"approverRoles,omitempty" json:"approverRoles,omitempty"`
+	// Expiry is how long an approval or rejection stays valid, if set
+	Expiry time.Duration `yaml:"expiry,omitempty" json:"expiry,omitempty"`
+}
+
+// ReleaseApproval records the decision of a user to approve or reject a release
+type ReleaseApproval struct {
+	User      *User                   `json:"user"`
+	Decision  ReleaseApprovalDecision `json:"decision"`
+	Comment   string                  `json:"comment,omitempty"`
+	DecidedAt time.Time               `json:"decidedAt"`
+}
+
+// ReleaseApprovalEvaluation tells whether a release may start, and otherwise why not
+type ReleaseApprovalEvaluation struct {
+	State             ReleaseApprovalState `json:"state"`
+	MayStart          bool                 `json:"mayStart"`
+	Approvals         int                  `json:"approvals"`
+	RequiredApprovals int                  `json:"requiredApprovals"`
+	Reasons           []string             `json:"reasons,omitempty"`
+}
+
+// Validate checks whether the policy has targets and a valid number of required approvals
+func (p *ReleaseApprovalPolicy) Validate() error {
+	if len(p.Targets) == 0 {
+		return fmt.Errorf("Release approval policy %v needs at least one target", p.Name)
+	}
+	if p.RequiredApprovals < 0 {
+		return fmt.Errorf("Required approvals for release approval policy %v can't be negative", p.Name)
+	}
+	if p.Expiry < 0 {
+		return fmt.Errorf("Expiry for release approval policy %v can't be negative", p.Name)
+	}
+
+	return nil
+}
+
+// GetRequiredApprovals returns the number of required approvals, defaulting to 1
+func (p *ReleaseApprovalPolicy) GetRequiredApprovals() int {
+	if p.RequiredApprovals <= 0 {
+		return 1
+	}
+	return p.RequiredApprovals
+}
+
+// AppliesTo returns true if the policy covers the release's target and action
+func (p *ReleaseApprovalPolicy) AppliesTo(release Release) bool {
+	if !containsString(p.Targets, release.Name) {
+		return false
+	}
+
+	return len(p.Actions) == 0 || containsString(p.Actions, release.Action)
+}
+
+// IsApprover returns true if the user is in one of the approver groups or has one of the approver roles
+func (p *ReleaseApprovalPolicy) IsApprover(user *User) bool {
+	if user == nil {
+		return false
+	}
+	if len(p.ApproverGroups) == 0 && len(p.ApproverRoles) == 0 {
+		return true
+	}
+
+	for _, g := range user.Groups {
+		if g != nil && (containsString(p.ApproverGroups, g.Name) || containsString(p.ApproverGroups, g.ID)) {
+			return true
+		}
+	}
+	for _, r := range p.ApproverRoles {
+		if user.HasRole(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Evaluate returns whether the release may start under this policy
+func (p *ReleaseApprovalPolicy) Evaluate(release Release, at time.Time) ReleaseApprovalEvaluation {
+
+	evaluation := ReleaseApprovalEvaluation{
+		RequiredApprovals: p.GetRequiredApprovals(),
+		Reasons:           []string{},
+	}
+
+	if !p.AppliesTo(release) {
+		evaluation.State = ReleaseApprovalStateNotRequired
+		evaluation.MayStart = true
+		evaluation.RequiredApprovals = 0
+		return evaluation
+	}
+
+	// a user can be recorded by id in one decision and by email in another, so decisions are grouped per person
+	identities := newUserIdentities()
+	for _, a := range release.Approvals {
+		if a != nil && a.User != nil {
+			identities.add(a.User)
+		}
+	}
+	triggerers := GetReleaseTriggerers(release)
+
+	latestDecisions := map[string]*ReleaseApproval{}
+	for _, a := range release.Approvals {
+		if a == nil || a.User == nil {
+			continue
+		}
+		key := identities.getName(a.User)
+		switch {
+		case key == "":
+			evaluation.Reasons = append(evaluation.Reasons, "Decision by a user without id or email address is ignored, because they can't be identified")
+			continue
+		case identities.isOneOf(a.User, triggerers):
+			evaluation.Reasons = append(evaluation.Reasons, fmt.Sprintf("Decision by %v is ignored, because they triggered the release", key))
+			continue
+		case !p.IsApprover(a.User):
+			evaluation.Reasons = append(evaluation.Reasons, fmt.Sprintf("Decision by %v is ignored, because they're not an approver for %v", key, p.Name))
+			continue
+		case p.Expiry > 0 && a.DecidedAt.Add(p.Expiry).Before(at):
+			evaluation.Reasons = append(evaluation.Reasons, fmt.Sprintf("Decision by %v expired at %v", key, a.DecidedAt.Add(p.Expiry).UTC().Format(time.RFC3339)))
+			continue
+		}
+		if latest, ok := latestDecisions[key]; !ok || a.DecidedAt.After(latest.DecidedAt) {
+			latestDecisions[key] = a
+		}
+	}
+
+	keys := make([]string, 0, len(latestDecisions))
+	for k := range latestDecisions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rejectedBy := []string{}
+	for _, k := range keys {
+		switch latestDecisions[k].Decision {
+		case ReleaseApprovalDecisionApproved:
+			evaluation.Approvals++
+		case ReleaseApprovalDecisionRejected:
+			rejectedBy = append(rejectedBy, k)
+		}
+	}
+
+	switch {
+	case len(rejectedBy) > 0:
+		evaluation.State = ReleaseApprovalStateRejected
+		evaluation.Reasons = append(evaluation.Reasons, fmt.Sprintf("Release is rejected by %v", strings.Join(rejectedBy, ", ")))
+	case evaluation.Approvals < evaluation.RequiredApprovals:
+		evaluation.State = ReleaseApprovalStateAwaiting
+		evaluation.Reasons = append(evaluation.Reasons, fmt.Sprintf("Release has %v of %v required approvals for %v", evaluation.Approvals, evaluation.RequiredApprovals, p.Name))
+	default:
+		evaluation.State = ReleaseApprovalStateApproved
+		evaluation.MayStart = true
+	}
+
+	return evaluation
+}
+
+// EvaluateReleaseApprovalPolicies returns whether the release may start under all policies that apply to it
+func EvaluateReleaseApprovalPolicies(policies []*ReleaseApprovalPolicy, release Release, at time.Time) ReleaseApprovalEvaluation {
+
+	evaluation := ReleaseApprovalEvaluation{
+		State:    ReleaseApprovalStateNotRequired,
+		MayStart: true,
+		Reasons:  []string{},
+	}
+
+	for _, p := range policies {
+		if p == nil || !p.AppliesTo(release) {
+			continue
+		}
+
+		policyEvaluation := p.Evaluate(release, at)
+		evaluation.Approvals += policyEvaluation.Approvals
+		evaluation.RequiredApprovals += policyEvaluation.RequiredApprovals
+		evaluation.Reasons = append(evaluation.Reasons, policyEvaluation.Reasons...)
+		evaluation.MayStart = evaluation.MayStart && policyEvaluation.MayStart
+
+		switch {
+		case policyEvaluation.State == ReleaseApprovalStateRejected || evaluation.State == ReleaseApprovalStateRejected:
+			evaluation.State = ReleaseApprovalStateRejected
+		case policyEvaluation.State == ReleaseApprovalStateAwaiting || evaluation.State == ReleaseApprovalStateAwaiting:
+			evaluation.State = ReleaseApprovalStateAwaiting
+		default:
+			evaluation.State = ReleaseApprovalStateApproved
+		}
+	}
+
+	return evaluation
+}
+
+// GetReleaseStatus returns the status for a release that hasn't started yet
+func (e ReleaseApprovalEvaluation) GetReleaseStatus() Status {
+	switch e.State {
+	case ReleaseApprovalStateAwaiting:
+		return StatusAwaitingApproval
+	case ReleaseApprovalStateRejected:
+		return StatusCanceled
+	}
+
+	return StatusPending
+}
+
+// GetReleaseTriggerers returns the user ids of the manual trigger events of the release
+func GetReleaseTriggerers(release Release) []string {
+	triggerers := []string{}
+	for _, e := range release.Events {
+		if e.Manual != nil && e.Manual.UserID != "" {
+			triggerers = append(triggerers, e.Manual.UserID)
+		}
+	}
+
+	return triggerers
+}
+
+// userIdentities groups the ids and email addresses of users that belong to the same person
+type userIdentities struct {
+	parents map[string]string
+	names   map[string]string
+}
+
+func newUserIdentities() *userIdentities {
+	return &userIdentities{
+		parents: map[string]string{},
+		names:   map[string]string{},
+	}
+}
+
+// add links the id and email addresses of the user to each other
+func (ui *userIdentities) add(user *User) {
+	keys := getUserIdentityKeys(user)
+	if len(keys) == 0 {
+		return
+	}
+	for _, k := range keys {
+		if _, ok := ui.parents[k]; !ok {
+			ui.parents[k] = k
+		}
+	}
+	for _, k := range keys[1:] {
+		if a, b := ui.getRoot(keys[0]), ui.getRoot(k); a != b {
+			ui.parents[b] = a
+		}
+	}
+}
+
+func (ui *userIdentities) getRoot(key string) string {
+	for ui.parents[key] != key {
+		key = ui.parents[key]
+	}
+
+	return key
+}
+
+// getName returns the same name for all users of a person, preferring an email address over an id
+func (ui *userIdentities) getName(user *User) string {
+	keys := getUserIdentityKeys(user)
+	if len(keys) == 0 {
+		return ""
+	}
+	root := ui.getRoot(keys[0])
+	if name, ok := ui.names[root]; ok {
+		return name
+	}
+
+	name := ""
+	for k := range ui.parents {
+		if ui.getRoot(k) != root {
+			continue
+		}
+		isEmail, nameIsEmail := strings.Contains(k, "@"), strings.Contains(name, "@")
+		if name == "" || (isEmail && !nameIsEmail) || (isEmail == nameIsEmail && k < name) {
+			name = k
+		}
+	}
+	ui.names[root] = name
+
+	return name
+}
+
+// isOneOf returns true if any id or email address of the person is in userIDs, ignoring case
+func (ui *userIdentities) isOneOf(user *User, userIDs []string) bool {
+	keys := getUserIdentityKeys(user)
+	if len(keys) == 0 {
+		return false
+	}
+	root := ui.getRoot(keys[0])
+	for _, id := range userIDs {
+		id = strings.ToLower(id)
+		if _, ok := ui.parents[id]; ok && ui.getRoot(id) == root {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getUserIdentityKeys returns the lowercased id and email addresses of the user
+func getUserIdentityKeys(user *User) []string {
+	keys := []string{}
+	if user.ID != "" {
+		keys = append(keys, strings.ToLower(user.ID))
+	}
+	if user.Email != "" {
+		keys = append(keys, strings.ToLower(user.Email))
+	}
+	for _, i := range user.Identities {
+		if i != nil && i.Email != "" {
+			keys = append(keys, strings.ToLower(i.Email))
+		}
+	}
+
+	return keys
+}
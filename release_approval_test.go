@@ -0,0 +1,237 @@
+package contracts
+
+import (
+	"testing"
+	"time"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseApprovalPolicyEvaluate(t *testing.T) {
+	t.Run("ReturnsNotRequiredForOtherTargets", func(t *testing.T) {
+
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}}
+		release := Release{Name: "staging", ReleaseVersion: "1.0.5"}
+
+		// act
+		evaluation := policy.Evaluate(release, time.Now())
+
+		assert.Equal(t, ReleaseApprovalStateNotRequired, evaluation.State)
+		assert.True(t, evaluation.MayStart)
+		assert.Equal(t, StatusPending, evaluation.GetReleaseStatus())
+	})
+
+	t.Run("ReturnsAwaitingWithoutApprovals", func(t *testing.T) {
+
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, RequiredApprovals: 1}
+		release := Release{Name: "production", ReleaseVersion: "1.0.5"}
+
+		// act
+		evaluation := policy.Evaluate(release, time.Now())
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.False(t, evaluation.MayStart)
+		assert.Equal(t, []string{"Release has 0 of 1 required approvals for four-eyes"}, evaluation.Reasons)
+		assert.Equal(t, StatusAwaitingApproval, evaluation.GetReleaseStatus())
+	})
+
+	t.Run("IgnoresApprovalByTriggerer", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, ApproverGroups: []string{"payments"}}
+		release := Release{
+			Name: "production",
+			Events: []manifest.EstafetteEvent{
+				{Manual: &manifest.EstafetteManualEvent{UserID: "me@estafette.io"}},
+			},
+			Approvals: []*ReleaseApproval{
+				{User: &User{ID: "1", Identities: []*UserIdentity{{Email: "ME@estafette.io"}}, Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.Equal(t, "Decision by me@estafette.io is ignored, because they triggered the release", evaluation.Reasons[0])
+	})
+
+	t.Run("IgnoresApprovalByTriggererRecordedByIdOnly", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, ApproverGroups: []string{"payments"}}
+		release := Release{
+			Name: "production",
+			Events: []manifest.EstafetteEvent{
+				{Manual: &manifest.EstafetteManualEvent{UserID: "them@estafette.io"}},
+				{Manual: &manifest.EstafetteManualEvent{UserID: "me@estafette.io"}},
+			},
+			Approvals: []*ReleaseApproval{
+				{User: &User{ID: "1", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+				{User: &User{ID: "1", Email: "me@estafette.io", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Hour)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.Equal(t, 0, evaluation.Approvals)
+	})
+
+	t.Run("IgnoresApprovalByUserWithoutIdentity", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}}
+		release := Release{
+			Name: "production",
+			Events: []manifest.EstafetteEvent{
+				{Manual: &manifest.EstafetteManualEvent{UserID: "me@estafette.io"}},
+			},
+			Approvals: []*ReleaseApproval{
+				{User: &User{Name: "Me"}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.Equal(t, 0, evaluation.Approvals)
+		assert.Equal(t, "Decision by a user without id or email address is ignored, because they can't be identified", evaluation.Reasons[0])
+	})
+
+	t.Run("IgnoresApprovalByNonApproverAndExpiredApproval", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, ApproverGroups: []string{"payments"}, Expiry: 24 * time.Hour}
+		release := Release{
+			Name: "production",
+			Approvals: []*ReleaseApproval{
+				{User: &User{Email: "you@estafette.io", Groups: []*Group{{Name: "checkout"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+				{User: &User{Email: "them@estafette.io", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-25 * time.Hour)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.Equal(t, []string{
+			"Decision by you@estafette.io is ignored, because they're not an approver for four-eyes",
+			"Decision by them@estafette.io expired at 2021-04-17T11:00:00Z",
+			"Release has 0 of 1 required approvals for four-eyes",
+		}, evaluation.Reasons)
+	})
+
+	t.Run("ReturnsApprovedForApprovalByRole", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		role := "release-manager"
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, ApproverGroups: []string{"payments"}, ApproverRoles: []string{"release-manager"}}
+		release := Release{
+			Name: "production",
+			Approvals: []*ReleaseApproval{
+				{User: &User{Email: "you@estafette.io", Roles: []*string{&role}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateApproved, evaluation.State)
+		assert.True(t, evaluation.MayStart)
+		assert.Equal(t, 1, evaluation.Approvals)
+	})
+
+	t.Run("ReturnsRejectedIfLatestDecisionOfApproverIsRejection", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, ApproverGroups: []string{"payments"}}
+		user := &User{Email: "you@estafette.io", Groups: []*Group{{Name: "payments"}}}
+		release := Release{
+			Name: "production",
+			Approvals: []*ReleaseApproval{
+				{User: user, Decision: ReleaseApprovalDecisionRejected, DecidedAt: at.Add(-time.Minute)},
+				{User: user, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Hour)},
+				{User: &User{Email: "them@estafette.io", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Hour)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateRejected, evaluation.State)
+		assert.False(t, evaluation.MayStart)
+		assert.Equal(t, []string{"Release is rejected by you@estafette.io"}, evaluation.Reasons)
+		assert.Equal(t, StatusCanceled, evaluation.GetReleaseStatus())
+	})
+
+	t.Run("CountsEachApproverOnce", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, RequiredApprovals: 2, ApproverGroups: []string{"payments"}}
+		user := &User{Email: "you@estafette.io", Groups: []*Group{{Name: "payments"}}}
+		release := Release{
+			Name: "production",
+			Approvals: []*ReleaseApproval{
+				{User: user, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+				{User: user, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Hour)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.Equal(t, 1, evaluation.Approvals)
+	})
+
+	t.Run("CountsApproverRecordedByIdAndByEmailOnce", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policy := &ReleaseApprovalPolicy{Name: "four-eyes", Targets: []string{"production"}, RequiredApprovals: 2, ApproverGroups: []string{"payments"}}
+		release := Release{
+			Name: "production",
+			Approvals: []*ReleaseApproval{
+				{User: &User{ID: "2", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+				{User: &User{Email: "you@estafette.io", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Hour)},
+				{User: &User{ID: "2", Email: "you@estafette.io", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-2 * time.Hour)},
+			},
+		}
+
+		// act
+		evaluation := policy.Evaluate(release, at)
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.Equal(t, 1, evaluation.Approvals)
+		assert.Equal(t, []string{"Release has 1 of 2 required approvals for four-eyes"}, evaluation.Reasons)
+	})
+}
+
+func TestEvaluateReleaseApprovalPolicies(t *testing.T) {
+	t.Run("RequiresAllApplicablePolicies", func(t *testing.T) {
+
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+		policies := []*ReleaseApprovalPolicy{
+			{Name: "four-eyes", Targets: []string{"production"}, ApproverGroups: []string{"payments"}},
+			{Name: "security", Targets: []string{"production"}, ApproverGroups: []string{"security"}},
+			{Name: "staging", Targets: []string{"staging"}},
+		}
+		release := Release{
+			Name: "production",
+			Approvals: []*ReleaseApproval{
+				{User: &User{Email: "you@estafette.io", Groups: []*Group{{Name: "payments"}}}, Decision: ReleaseApprovalDecisionApproved, DecidedAt: at.Add(-time.Minute)},
+			},
+		}
+
+		// act
+		evaluation := EvaluateReleaseApprovalPolicies(policies, release, at)
+
+		assert.Equal(t, ReleaseApprovalStateAwaiting, evaluation.State)
+		assert.False(t, evaluation.MayStart)
+		assert.Equal(t, 1, evaluation.Approvals)
+		assert.Equal(t, 2, evaluation.RequiredApprovals)
+	})
+}
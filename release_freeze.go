@@ -0,0 +1,188 @@
+package contracts
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+const releaseFreezeMaxOccurrences = 1000
+
+// ReleaseFreezeWindow blocks releases to its targets, either recurring from a cron schedule for a duration or once between an absolute start and end
+type ReleaseFreezeWindow struct {
+	Name string `yaml:"name" json:"name"`
+	// Targets limits the window to these release targets, or all targets if empty
+	Targets []string `yaml:"targets,omitempty" json:"targets,omitempty"`
+
+	// Cron is a standard <minute> <hour> <day of month> <month> <day of week> schedule at which a recurring window starts
+	Cron     string        `yaml:"cron,omitempty" json:"cron,omitempty"`
+	Duration time.Duration `yaml:"duration,omitempty" json:"duration,omitempty"`
+
+	Start *time.Time `yaml:"start,omitempty" json:"start,omitempty"`
+	End   *time.Time `yaml:"end,omitempty" json:"end,omitempty"`
+
+	// Timezone is the IANA time zone the cron schedule is evaluated in, defaulting to UTC
+	Timezone string `yaml:"timezone,omitempty" json:"timezone,omitempty"`
+
+	// ExemptGroups and ExemptActions allow releases of pipelines in these groups, or with these release actions, during the window
+	ExemptGroups  []string `yaml:"exemptGroups,omitempty" json:"exemptGroups,omitempty"`
+	ExemptActions []string `yaml:"exemptActions,omitempty" json:"exemptActions,omitempty"`
+}
+
+// ReleaseFreezePeriod is a single occurrence of a freeze window
+type ReleaseFreezePeriod struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// ReleaseFreezeEvaluation tells whether a release is allowed at a time, and otherwise which windows block it and when releasing is allowed again
+type ReleaseFreezeEvaluation struct {
+	Allowed       bool                  `json:"allowed"`
+	ActivePeriods []ReleaseFreezePeriod `json:"activePeriods,omitempty"`
+	NextAllowedAt *time.Time            `json:"nextAllowedAt,omitempty"`
+}
+
+// Validate checks whether the window is either recurring with a valid cron schedule and duration or absolute with start before end
+func (w *ReleaseFreezeWindow) Validate() error {
+	if _, err := w.getLocation(); err != nil {
+		return err
+	}
+
+	switch {
+	case w.Cron != "" && (w.Start != nil || w.End != nil):
+		return fmt.Errorf("Release freeze window %v can't have both a cron schedule and a start or end", w.Name)
+	case w.Cron != "":
+		if _, err := cron.ParseStandard(w.Cron); err != nil {
+			return fmt.Errorf("Release freeze window %v has invalid cron schedule %q: %w", w.Name, w.Cron, err)
+		}
+		if w.Duration <= 0 {
+			return fmt.Errorf("Release freeze window %v needs a positive duration for its cron schedule", w.Name)
+		}
+	case w.Start != nil && w.End != nil:
+		if !w.Start.Before(*w.End) {
+			return fmt.Errorf("Release freeze window %v starts at or after its end", w.Name)
+		}
+	default:
+		return fmt.Errorf("Release freeze window %v needs either a cron schedule and duration or a start and end", w.Name)
+	}
+
+	return nil
+}
+
+// AppliesTo returns true if the window covers the target and the release isn't exempt by group or action
+func (w *ReleaseFreezeWindow) AppliesTo(release Release, target string) bool {
+	if len(w.Targets) > 0 && !containsString(w.Targets, target) {
+		return false
+	}
+	if release.Action != "" && containsString(w.ExemptActions, release.Action) {
+		return false
+	}
+	for _, g := range release.Groups {
+		if g != nil && (containsString(w.ExemptGroups, g.Name) || containsString(w.ExemptGroups, g.ID)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// GetActivePeriod returns the occurrence of the window that includes at, or nil if the window isn't active at that time
+func (w *ReleaseFreezeWindow) GetActivePeriod(at time.Time) *ReleaseFreezePeriod {
+	periods := w.GetPeriods(at, at.Add(time.Nanosecond))
+	if len(periods) == 0 {
+		return nil
+	}
+
+	return &periods[0]
+}
+
+// GetPeriods returns the occurrences of the window that overlap with the range from since until until, for showing a release calendar
+func (w *ReleaseFreezeWindow) GetPeriods(since, until time.Time) []ReleaseFreezePeriod {
+	periods := []ReleaseFreezePeriod{}
+
+	if w.Cron == "" {
+		if w.Start != nil && w.End != nil && w.Start.Before(until) && w.End.After(since) {
+			periods = append(periods, ReleaseFreezePeriod{Name: w.Name, Start: *w.Start, End: *w.End})
+		}
+		return periods
+	}
+
+	schedule, err := cron.ParseStandard(w.Cron)
+	if err != nil || w.Duration <= 0 {
+		return periods
+	}
+	location, err := w.getLocation()
+	if err != nil {
+		return periods
+	}
+
+	// the schedule returns the first start strictly after the time passed, so a period that started exactly a duration ago has already ended
+	start := schedule.Next(since.Add(-w.Duration).In(location))
+	for i := 0; i < releaseFreezeMaxOccurrences && !start.IsZero() && start.Before(until); i++ {
+		periods = append(periods, ReleaseFreezePeriod{Name: w.Name, Start: start, End: start.Add(w.Duration)})
+		start = schedule.Next(start)
+	}
+
+	return periods
+}
+
+func (w *ReleaseFreezeWindow) getLocation() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.UTC, nil
+	}
+
+	location, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("Release freeze window %v has invalid timezone %q: %w", w.Name, w.Timezone, err)
+	}
+
+	return location, nil
+}
+
+// EvaluateReleaseFreezeWindows returns whether the release to the target is allowed at a time, or else when it will be
+func EvaluateReleaseFreezeWindows(windows []*ReleaseFreezeWindow, release Release, target string, at time.Time) ReleaseFreezeEvaluation {
+
+	applicableWindows := []*ReleaseFreezeWindow{}
+	for _, w := range windows {
+		if w != nil && w.AppliesTo(release, target) {
+			applicableWindows = append(applicableWindows, w)
+		}
+	}
+
+	getActivePeriods := func(t time.Time) []ReleaseFreezePeriod {
+		periods := []ReleaseFreezePeriod{}
+		for _, w := range applicableWindows {
+			if p := w.GetActivePeriod(t); p != nil {
+				periods = append(periods, *p)
+			}
+		}
+		sort.SliceStable(periods, func(i, j int) bool {
+			return periods[i].End.Before(periods[j].End)
+		})
+		return periods
+	}
+
+	evaluation := ReleaseFreezeEvaluation{
+		ActivePeriods: getActivePeriods(at),
+	}
+	if len(evaluation.ActivePeriods) == 0 {
+		evaluation.Allowed = true
+		return evaluation
+	}
+
+	nextAllowedAt := at
+	activePeriods := evaluation.ActivePeriods
+	for i := 0; i < releaseFreezeMaxOccurrences && len(activePeriods) > 0; i++ {
+		nextAllowedAt = activePeriods[len(activePeriods)-1].End
+		activePeriods = getActivePeriods(nextAllowedAt)
+	}
+	if len(activePeriods) == 0 {
+		nextAllowedAt = nextAllowedAt.UTC()
+		evaluation.NextAllowedAt = &nextAllowedAt
+	}
+
+	return evaluation
+}
@@ -0,0 +1,166 @@
+package contracts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestReleaseFreezeWindowValidate(t *testing.T) {
+	t.Run("ReturnsNoErrorForWindowsFromYaml", func(t *testing.T) {
+
+		var windows []*ReleaseFreezeWindow
+		err := yaml.Unmarshal([]byte(`
+- name: weeknights
+  targets:
+  - production
+  cron: 0 18 * * 1-5
+  duration: 15h
+  timezone: Europe/Amsterdam
+  exemptActions:
+  - rollback
+- name: holidays
+  start: 2021-12-24T00:00:00Z
+  end: 2022-01-03T00:00:00Z
+  exemptGroups:
+  - platform
+`), &windows)
+		if !assert.Nil(t, err) || !assert.Equal(t, 2, len(windows)) {
+			return
+		}
+
+		for _, w := range windows {
+			// act
+			err = w.Validate()
+
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, &ReleaseFreezeWindow{Name: "weeknights", Targets: []string{"production"}, Cron: "0 18 * * 1-5", Duration: 15 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}}, windows[0])
+		assert.True(t, time.Date(2021, 12, 24, 0, 0, 0, 0, time.UTC).Equal(*windows[1].Start))
+	})
+
+	t.Run("ReturnsErrorForInvalidWindows", func(t *testing.T) {
+
+		start := time.Date(2021, 12, 24, 0, 0, 0, 0, time.UTC)
+		windows := []*ReleaseFreezeWindow{
+			{Name: "no-schedule"},
+			{Name: "invalid-cron", Cron: "every day", Duration: time.Hour},
+			{Name: "no-duration", Cron: "0 18 * * *"},
+			{Name: "invalid-timezone", Cron: "0 18 * * *", Duration: time.Hour, Timezone: "Europe/Utrecht"},
+			{Name: "end-before-start", Start: &start, End: &start},
+			{Name: "both", Cron: "0 18 * * *", Duration: time.Hour, Start: &start},
+		}
+
+		for _, w := range windows {
+			// act
+			err := w.Validate()
+
+			assert.NotNil(t, err, w.Name)
+		}
+	})
+}
+
+func TestReleaseFreezeWindowGetPeriods(t *testing.T) {
+	t.Run("ReturnsOccurrencesInTimezoneOverlappingRange", func(t *testing.T) {
+
+		window := &ReleaseFreezeWindow{Name: "weeknights", Targets: []string{"production"}, Cron: "0 18 * * 1-5", Duration: 15 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}}
+
+		// act
+		periods := window.GetPeriods(time.Date(2021, 4, 16, 6, 0, 0, 0, time.UTC), time.Date(2021, 4, 20, 0, 0, 0, 0, time.UTC))
+
+		if !assert.Equal(t, 3, len(periods)) {
+			return
+		}
+		assert.True(t, time.Date(2021, 4, 15, 16, 0, 0, 0, time.UTC).Equal(periods[0].Start))
+		assert.True(t, time.Date(2021, 4, 16, 7, 0, 0, 0, time.UTC).Equal(periods[0].End))
+		assert.True(t, time.Date(2021, 4, 16, 16, 0, 0, 0, time.UTC).Equal(periods[1].Start))
+		assert.True(t, time.Date(2021, 4, 19, 16, 0, 0, 0, time.UTC).Equal(periods[2].Start))
+	})
+}
+
+func TestEvaluateReleaseFreezeWindows(t *testing.T) {
+	t.Run("ReturnsAllowedDuringOfficeHours", func(t *testing.T) {
+
+		holidaysStart := time.Date(2021, 12, 24, 0, 0, 0, 0, time.UTC)
+		holidaysEnd := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+		windows := []*ReleaseFreezeWindow{
+			{Name: "weeknights", Targets: []string{"production"}, Cron: "0 18 * * 1-5", Duration: 15 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}},
+			{Name: "weekends", Targets: []string{"production"}, Cron: "0 18 * * 5", Duration: 63 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}},
+			{Name: "holidays", Start: &holidaysStart, End: &holidaysEnd, ExemptGroups: []string{"platform"}},
+		}
+
+		// act
+		evaluation := EvaluateReleaseFreezeWindows(windows, Release{Name: "production"}, "production", time.Date(2021, 4, 15, 12, 0, 0, 0, time.UTC))
+
+		assert.True(t, evaluation.Allowed)
+		assert.Nil(t, evaluation.NextAllowedAt)
+	})
+
+	t.Run("ReturnsEndOfWeekendForFridayEvening", func(t *testing.T) {
+
+		windows := []*ReleaseFreezeWindow{
+			{Name: "weeknights", Targets: []string{"production"}, Cron: "0 18 * * 1-5", Duration: 15 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}},
+			{Name: "weekends", Targets: []string{"production"}, Cron: "0 18 * * 5", Duration: 63 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}},
+		}
+
+		// act
+		evaluation := EvaluateReleaseFreezeWindows(windows, Release{Name: "production"}, "production", time.Date(2021, 4, 16, 17, 0, 0, 0, time.UTC))
+
+		assert.False(t, evaluation.Allowed)
+		assert.Equal(t, 2, len(evaluation.ActivePeriods))
+		if assert.NotNil(t, evaluation.NextAllowedAt) {
+			assert.Equal(t, time.Date(2021, 4, 19, 7, 0, 0, 0, time.UTC), *evaluation.NextAllowedAt)
+		}
+	})
+
+	t.Run("ReturnsAllowedForOtherTargetOrExemptAction", func(t *testing.T) {
+
+		windows := []*ReleaseFreezeWindow{
+			{Name: "weeknights", Targets: []string{"production"}, Cron: "0 18 * * 1-5", Duration: 15 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}},
+			{Name: "weekends", Targets: []string{"production"}, Cron: "0 18 * * 5", Duration: 63 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}},
+		}
+		at := time.Date(2021, 4, 16, 17, 0, 0, 0, time.UTC)
+
+		// act
+		stagingEvaluation := EvaluateReleaseFreezeWindows(windows, Release{Name: "staging"}, "staging", at)
+		rollbackEvaluation := EvaluateReleaseFreezeWindows(windows, Release{Name: "production", Action: "rollback"}, "production", at)
+
+		assert.True(t, stagingEvaluation.Allowed)
+		assert.True(t, rollbackEvaluation.Allowed)
+	})
+
+	t.Run("ReturnsEndOfHolidaysFollowedByWeeknightForAllTargets", func(t *testing.T) {
+
+		holidaysStart := time.Date(2021, 12, 24, 0, 0, 0, 0, time.UTC)
+		holidaysEnd := time.Date(2022, 1, 3, 17, 30, 0, 0, time.UTC)
+		windows := []*ReleaseFreezeWindow{
+			{Name: "weeknights", Targets: []string{"production"}, Cron: "0 18 * * 1-5", Duration: 15 * time.Hour, Timezone: "Europe/Amsterdam", ExemptActions: []string{"rollback"}},
+			{Name: "holidays", Start: &holidaysStart, End: &holidaysEnd, ExemptGroups: []string{"platform"}},
+		}
+
+		// act
+		evaluation := EvaluateReleaseFreezeWindows(windows, Release{Name: "production"}, "production", time.Date(2021, 12, 27, 12, 0, 0, 0, time.UTC))
+
+		assert.False(t, evaluation.Allowed)
+		if assert.NotNil(t, evaluation.NextAllowedAt) {
+			assert.Equal(t, time.Date(2022, 1, 4, 8, 0, 0, 0, time.UTC), *evaluation.NextAllowedAt)
+		}
+	})
+
+	t.Run("ReturnsAllowedForExemptGroupDuringHolidays", func(t *testing.T) {
+
+		holidaysStart := time.Date(2021, 12, 24, 0, 0, 0, 0, time.UTC)
+		holidaysEnd := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+		windows := []*ReleaseFreezeWindow{
+			{Name: "holidays", Start: &holidaysStart, End: &holidaysEnd, ExemptGroups: []string{"platform"}},
+		}
+		release := Release{Name: "staging", Groups: []*Group{{Name: "platform"}}}
+
+		// act
+		evaluation := EvaluateReleaseFreezeWindows(windows, release, "staging", time.Date(2021, 12, 27, 12, 0, 0, 0, time.UTC))
+
+		assert.True(t, evaluation.Allowed)
+	})
+}
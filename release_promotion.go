@@ -0,0 +1,231 @@
+package contracts
+
+import (
+	"fmt"
+	"time"
+)
+
+type ReleasePromotionGate string
+
+const (
+	ReleasePromotionGateUnknown         ReleasePromotionGate = ""
+	ReleasePromotionGateTarget          ReleasePromotionGate = "target"
+	ReleasePromotionGateNotRunning      ReleasePromotionGate = "not-running"
+	ReleasePromotionGatePreviousTarget  ReleasePromotionGate = "previous-target"
+	ReleasePromotionGateSoakTime        ReleasePromotionGate = "soak-time"
+	ReleasePromotionGateVulnerabilities ReleasePromotionGate = "vulnerabilities"
+)
+
+// ReleasePromotionPolicy orders the release targets a version is promoted through, like development, staging and production
+type ReleasePromotionPolicy struct {
+	Stages []*ReleasePromotionStage `yaml:"stages" json:"stages"`
+}
+
+// ReleasePromotionStage is a release target (and optionally action) in a promotion policy with the gates for promoting a version to it
+type ReleasePromotionStage struct {
+	Target string `yaml:"target" json:"target"`
+	Action string `yaml:"action,omitempty" json:"action,omitempty"`
+	// SoakTime is the time a version has to run successfully on the previous stage before it can be promoted to this one
+	SoakTime time.Duration `yaml:"soakTime,omitempty" json:"soakTime,omitempty"`
+	// BlockingVulnerabilityLevel blocks promotion if the version has vulnerabilities of this level or higher
+	BlockingVulnerabilityLevel NotificationLevel `yaml:"blockingVulnerabilityLevel,omitempty" json:"blockingVulnerabilityLevel,omitempty"`
+}
+
+// ReleasePromotion is a candidate promotion of a version to a stage, which is allowed if all gates pass
+type ReleasePromotion struct {
+	Target  string                       `json:"target"`
+	Action  string                       `json:"action,omitempty"`
+	Version string                       `json:"version"`
+	Allowed bool                         `json:"allowed"`
+	Gates   []ReleasePromotionGateResult `json:"gates,omitempty"`
+}
+
+// ReleasePromotionGateResult explains why a gate passed or blocks a promotion
+type ReleasePromotionGateResult struct {
+	Gate   ReleasePromotionGate `json:"gate"`
+	Passed bool                 `json:"passed"`
+	Reason string               `json:"reason"`
+}
+
+// Validate checks whether the policy has stages and doesn't list a target and action twice
+func (p *ReleasePromotionPolicy) Validate() error {
+	if len(p.Stages) == 0 {
+		return fmt.Errorf("Release promotion policy needs at least one stage")
+	}
+
+	stages := map[string]bool{}
+	for _, s := range p.Stages {
+		if s == nil || s.Target == "" {
+			return fmt.Errorf("Release promotion stage needs a target")
+		}
+		if stages[s.getName()] {
+			return fmt.Errorf("Release promotion stage %v is listed more than once", s.getName())
+		}
+		stages[s.getName()] = true
+
+		if s.SoakTime < 0 {
+			return fmt.Errorf("Soak time for release promotion stage %v can't be negative", s.getName())
+		}
+		if s.BlockingVulnerabilityLevel != NotificationLevelUnknown && s.BlockingVulnerabilityLevel.Severity() == 0 {
+			return fmt.Errorf("Blocking vulnerability level %q for release promotion stage %v is invalid", s.BlockingVulnerabilityLevel, s.getName())
+		}
+	}
+
+	return nil
+}
+
+// Evaluate returns a promotion with the result of each gate for every stage the version hasn't been released to successfully yet
+func (p *ReleasePromotionPolicy) Evaluate(pipeline Pipeline, version string, releases []*Release, records []*NotificationRecord, at time.Time) []ReleasePromotion {
+
+	promotions := []ReleasePromotion{}
+
+	for i, s := range p.Stages {
+		if s == nil {
+			continue
+		}
+		if getLastSucceededRelease(releases, s.Target, s.Action, version) != nil {
+			continue
+		}
+
+		promotion := ReleasePromotion{
+			Target:  s.Target,
+			Action:  s.Action,
+			Version: version,
+			Gates:   []ReleasePromotionGateResult{},
+		}
+
+		promotion.Gates = append(promotion.Gates, s.evaluateTargetGate(pipeline))
+		promotion.Gates = append(promotion.Gates, s.evaluateNotRunningGate(releases))
+
+		if i > 0 && p.Stages[i-1] != nil {
+			previous := p.Stages[i-1]
+			previousRelease := getLastSucceededRelease(releases, previous.Target, previous.Action, version)
+			promotion.Gates = append(promotion.Gates, evaluatePreviousTargetGate(previous, previousRelease, version))
+			if s.SoakTime > 0 {
+				promotion.Gates = append(promotion.Gates, s.evaluateSoakTimeGate(previous, previousRelease, at))
+			}
+		}
+
+		if s.BlockingVulnerabilityLevel != NotificationLevelUnknown {
+			promotion.Gates = append(promotion.Gates, s.evaluateVulnerabilitiesGate(pipeline, records, version))
+		}
+
+		promotion.Allowed = true
+		for _, g := range promotion.Gates {
+			if !g.Passed {
+				promotion.Allowed = false
+				break
+			}
+		}
+
+		promotions = append(promotions, promotion)
+	}
+
+	return promotions
+}
+
+// GetAllowedReleasePromotions returns the promotions for which all gates passed
+func GetAllowedReleasePromotions(promotions []ReleasePromotion) []ReleasePromotion {
+	allowedPromotions := []ReleasePromotion{}
+	for _, p := range promotions {
+		if p.Allowed {
+			allowedPromotions = append(allowedPromotions, p)
+		}
+	}
+
+	return allowedPromotions
+}
+
+func (s *ReleasePromotionStage) getName() string {
+	return getReleaseTargetName(s.Target, s.Action)
+}
+
+func (s *ReleasePromotionStage) evaluateTargetGate(pipeline Pipeline) ReleasePromotionGateResult {
+	for _, rt := range pipeline.ReleaseTargets {
+		if rt.Name != s.Target {
+			continue
+		}
+		if s.Action == "" {
+			return ReleasePromotionGateResult{Gate: ReleasePromotionGateTarget, Passed: true, Reason: fmt.Sprintf("Pipeline has release target %v", s.Target)}
+		}
+		for _, a := range rt.Actions {
+			if a.Name == s.Action {
+				return ReleasePromotionGateResult{Gate: ReleasePromotionGateTarget, Passed: true, Reason: fmt.Sprintf("Pipeline has release target %v", s.getName())}
+			}
+		}
+		return ReleasePromotionGateResult{Gate: ReleasePromotionGateTarget, Reason: fmt.Sprintf("Release target %v has no action %v", s.Target, s.Action)}
+	}
+
+	return ReleasePromotionGateResult{Gate: ReleasePromotionGateTarget, Reason: fmt.Sprintf("Pipeline has no release target %v", s.Target)}
+}
+
+func (s *ReleasePromotionStage) evaluateNotRunningGate(releases []*Release) ReleasePromotionGateResult {
+	for _, r := range releases {
+		if r != nil && r.Name == s.Target && (r.ReleaseStatus == StatusPending || r.ReleaseStatus == StatusAwaitingApproval || r.ReleaseStatus == StatusRunning || r.ReleaseStatus == StatusCanceling) {
+			return ReleasePromotionGateResult{Gate: ReleasePromotionGateNotRunning, Reason: fmt.Sprintf("Version %v is being released to %v", r.ReleaseVersion, s.Target)}
+		}
+	}
+
+	return ReleasePromotionGateResult{Gate: ReleasePromotionGateNotRunning, Passed: true, Reason: fmt.Sprintf("No release to %v is running", s.Target)}
+}
+
+func evaluatePreviousTargetGate(previous *ReleasePromotionStage, previousRelease *Release, version string) ReleasePromotionGateResult {
+	if previousRelease == nil {
+		return ReleasePromotionGateResult{Gate: ReleasePromotionGatePreviousTarget, Reason: fmt.Sprintf("Version %v hasn't been released successfully to %v", version, previous.getName())}
+	}
+
+	return ReleasePromotionGateResult{Gate: ReleasePromotionGatePreviousTarget, Passed: true, Reason: fmt.Sprintf("Version %v has been released successfully to %v", version, previous.getName())}
+}
+
+func (s *ReleasePromotionStage) evaluateSoakTimeGate(previous *ReleasePromotionStage, previousRelease *Release, at time.Time) ReleasePromotionGateResult {
+	if previousRelease == nil {
+		return ReleasePromotionGateResult{Gate: ReleasePromotionGateSoakTime, Reason: fmt.Sprintf("Soak time of %v on %v hasn't started", s.SoakTime, previous.getName())}
+	}
+
+	finishedAt := getReleaseFinishedAt(previousRelease)
+	if finishedAt == nil {
+		return ReleasePromotionGateResult{Gate: ReleasePromotionGateSoakTime, Reason: fmt.Sprintf("Release to %v has no finish time to start the soak time from", previous.getName())}
+	}
+
+	soakedAt := finishedAt.Add(s.SoakTime)
+	if at.Before(soakedAt) {
+		return ReleasePromotionGateResult{Gate: ReleasePromotionGateSoakTime, Reason: fmt.Sprintf("Soak time of %v on %v ends at %v", s.SoakTime, previous.getName(), soakedAt.UTC().Format(time.RFC3339))}
+	}
+
+	return ReleasePromotionGateResult{Gate: ReleasePromotionGateSoakTime, Passed: true, Reason: fmt.Sprintf("Soak time of %v on %v ended at %v", s.SoakTime, previous.getName(), soakedAt.UTC().Format(time.RFC3339))}
+}
+
+func (s *ReleasePromotionStage) evaluateVulnerabilitiesGate(pipeline Pipeline, records []*NotificationRecord, version string) ReleasePromotionGateResult {
+	count := 0
+	for _, nr := range records {
+		if nr == nil {
+			continue
+		}
+		isVersionRecord := (nr.LinkType == NotificationLinkTypePipeline && nr.LinkID == pipeline.GetFullRepoPath() && nr.PipelineDetail != nil && nr.PipelineDetail.Version == version) ||
+			(nr.LinkType == NotificationLinkTypeContainer && nr.ContainerDetail != nil && nr.ContainerDetail.Tag == version)
+		if isVersionRecord {
+			count += len(FilterNotifications(nr.Notifications, NotificationTypeVulnerability, s.BlockingVulnerabilityLevel))
+		}
+	}
+
+	if count > 0 {
+		return ReleasePromotionGateResult{Gate: ReleasePromotionGateVulnerabilities, Reason: fmt.Sprintf("Version %v has %v vulnerabilities of level %v or higher", version, count, s.BlockingVulnerabilityLevel)}
+	}
+
+	return ReleasePromotionGateResult{Gate: ReleasePromotionGateVulnerabilities, Passed: true, Reason: fmt.Sprintf("Version %v has no vulnerabilities of level %v or higher", version, s.BlockingVulnerabilityLevel)}
+}
+
+// getLastSucceededRelease returns the most recently inserted succeeded release of the version to the target and action
+func getLastSucceededRelease(releases []*Release, target, action, version string) *Release {
+	var lastRelease *Release
+	for _, r := range releases {
+		if r == nil || r.Name != target || r.Action != action || r.ReleaseVersion != version || r.ReleaseStatus != StatusSucceeded {
+			continue
+		}
+		if lastRelease == nil || compareTimePointers(r.InsertedAt, lastRelease.InsertedAt) > 0 {
+			lastRelease = r
+		}
+	}
+
+	return lastRelease
+}
@@ -0,0 +1,232 @@
+package contracts
+
+import (
+	"testing"
+	"time"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestReleasePromotionPolicyValidate(t *testing.T) {
+	t.Run("ReturnsNoErrorForPolicyFromYaml", func(t *testing.T) {
+
+		expectedPolicy := ReleasePromotionPolicy{
+			Stages: []*ReleasePromotionStage{
+				{Target: "development"},
+				{Target: "staging", SoakTime: time.Hour},
+				{Target: "production", Action: "deploy-stable", SoakTime: 24 * time.Hour, BlockingVulnerabilityLevel: NotificationLevelCritical},
+			},
+		}
+		var policy ReleasePromotionPolicy
+		err := yaml.Unmarshal([]byte(`
+stages:
+- target: development
+- target: staging
+  soakTime: 1h
+- target: production
+  action: deploy-stable
+  soakTime: 24h
+  blockingVulnerabilityLevel: critical
+`), &policy)
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		err = policy.Validate()
+
+		assert.Nil(t, err)
+		assert.Equal(t, expectedPolicy, policy)
+	})
+
+	t.Run("ReturnsErrorForDuplicateStage", func(t *testing.T) {
+
+		policy := ReleasePromotionPolicy{Stages: []*ReleasePromotionStage{{Target: "staging"}, {Target: "staging"}}}
+
+		// act
+		err := policy.Validate()
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorForInvalidVulnerabilityLevel", func(t *testing.T) {
+
+		policy := ReleasePromotionPolicy{Stages: []*ReleasePromotionStage{{Target: "staging", BlockingVulnerabilityLevel: "severe"}}}
+
+		// act
+		err := policy.Validate()
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestReleasePromotionPolicyEvaluate(t *testing.T) {
+	t.Run("ReturnsAllStagesWithOnlyFirstAllowedForUnreleasedVersion", func(t *testing.T) {
+
+		pipeline := Pipeline{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "estafette-ci-api",
+			ReleaseTargets: []ReleaseTarget{
+				{Name: "development"},
+				{Name: "staging"},
+				{Name: "production", Actions: []manifest.EstafetteReleaseAction{{Name: "deploy-canary"}, {Name: "deploy-stable"}}},
+			},
+		}
+		policy := ReleasePromotionPolicy{
+			Stages: []*ReleasePromotionStage{
+				{Target: "development"},
+				{Target: "staging", SoakTime: time.Hour},
+				{Target: "production", Action: "deploy-stable", SoakTime: 24 * time.Hour, BlockingVulnerabilityLevel: NotificationLevelCritical},
+			},
+		}
+		at := time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC)
+
+		// act
+		promotions := policy.Evaluate(pipeline, "1.0.5", nil, nil, at)
+
+		if !assert.Equal(t, 3, len(promotions)) {
+			return
+		}
+		assert.True(t, promotions[0].Allowed)
+		assert.False(t, promotions[1].Allowed)
+		assert.Equal(t, "Version 1.0.5 hasn't been released successfully to development", promotions[1].Gates[2].Reason)
+		assert.False(t, promotions[2].Allowed)
+		assert.Equal(t, []ReleasePromotion{promotions[0]}, GetAllowedReleasePromotions(promotions))
+	})
+
+	t.Run("BlocksPromotionUntilSoakTimeHasElapsed", func(t *testing.T) {
+
+		pipeline := Pipeline{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "estafette-ci-api",
+			ReleaseTargets: []ReleaseTarget{
+				{Name: "development"},
+				{Name: "staging"},
+				{Name: "production", Actions: []manifest.EstafetteReleaseAction{{Name: "deploy-canary"}, {Name: "deploy-stable"}}},
+			},
+		}
+		policy := ReleasePromotionPolicy{
+			Stages: []*ReleasePromotionStage{
+				{Target: "development"},
+				{Target: "staging", SoakTime: time.Hour},
+				{Target: "production", Action: "deploy-stable", SoakTime: 24 * time.Hour, BlockingVulnerabilityLevel: NotificationLevelCritical},
+			},
+		}
+		insertedAt := time.Date(2021, 4, 17, 11, 0, 0, 0, time.UTC)
+		duration := 10 * time.Minute
+		releases := []*Release{
+			{Name: "development", ReleaseVersion: "1.0.5", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt, Duration: &duration},
+		}
+
+		// act
+		promotions := policy.Evaluate(pipeline, "1.0.5", releases, nil, time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC))
+
+		if !assert.Equal(t, 2, len(promotions)) {
+			return
+		}
+		assert.Equal(t, "staging", promotions[0].Target)
+		assert.False(t, promotions[0].Allowed)
+		assert.Equal(t, ReleasePromotionGateResult{Gate: ReleasePromotionGateSoakTime, Reason: "Soak time of 1h0m0s on development ends at 2021-04-17T12:10:00Z"}, promotions[0].Gates[3])
+
+		promotions = policy.Evaluate(pipeline, "1.0.5", releases, nil, time.Date(2021, 4, 17, 12, 10, 0, 0, time.UTC))
+		assert.True(t, promotions[0].Allowed)
+	})
+
+	t.Run("BlocksPromotionForCriticalVulnerabilitiesInVersion", func(t *testing.T) {
+
+		pipeline := Pipeline{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "estafette-ci-api",
+			ReleaseTargets: []ReleaseTarget{
+				{Name: "development"},
+				{Name: "staging"},
+				{Name: "production", Actions: []manifest.EstafetteReleaseAction{{Name: "deploy-canary"}, {Name: "deploy-stable"}}},
+			},
+		}
+		policy := ReleasePromotionPolicy{
+			Stages: []*ReleasePromotionStage{
+				{Target: "development"},
+				{Target: "staging", SoakTime: time.Hour},
+				{Target: "production", Action: "deploy-stable", SoakTime: 24 * time.Hour, BlockingVulnerabilityLevel: NotificationLevelCritical},
+			},
+		}
+		insertedAt := time.Date(2021, 4, 15, 11, 0, 0, 0, time.UTC)
+		duration := 10 * time.Minute
+		releases := []*Release{
+			{Name: "development", ReleaseVersion: "1.0.5", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt, Duration: &duration},
+			{Name: "staging", ReleaseVersion: "1.0.5", ReleaseStatus: StatusSucceeded, InsertedAt: &insertedAt, Duration: &duration},
+		}
+		records := []*NotificationRecord{
+			{LinkType: NotificationLinkTypeContainer, LinkID: "estafette/estafette-ci-api", ContainerDetail: &ContainerLinkDetail{Tag: "1.0.4"}, Notifications: []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical}}},
+			{LinkType: NotificationLinkTypeContainer, LinkID: "estafette/estafette-ci-api", ContainerDetail: &ContainerLinkDetail{Tag: "1.0.5"}, Notifications: []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelHigh}}},
+		}
+
+		// act
+		promotions := policy.Evaluate(pipeline, "1.0.5", releases, records, time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC))
+
+		if !assert.Equal(t, 1, len(promotions)) {
+			return
+		}
+		assert.True(t, promotions[0].Allowed)
+
+		records = append(records, &NotificationRecord{LinkType: NotificationLinkTypePipeline, LinkID: "github.com/estafette/estafette-ci-api", PipelineDetail: &PipelineLinkDetail{Version: "1.0.5"}, Notifications: []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical}}})
+		promotions = policy.Evaluate(pipeline, "1.0.5", releases, records, time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC))
+		assert.False(t, promotions[0].Allowed)
+		assert.Equal(t, "Version 1.0.5 has 1 vulnerabilities of level critical or higher", promotions[0].Gates[4].Reason)
+	})
+
+	t.Run("IgnoresVulnerabilitiesOfSameVersionOfOtherPipeline", func(t *testing.T) {
+
+		pipeline := Pipeline{
+			RepoSource:     "github.com",
+			RepoOwner:      "estafette",
+			RepoName:       "estafette-ci-api",
+			ReleaseTargets: []ReleaseTarget{{Name: "production"}},
+		}
+		policy := ReleasePromotionPolicy{Stages: []*ReleasePromotionStage{{Target: "production", BlockingVulnerabilityLevel: NotificationLevelCritical}}}
+		records := []*NotificationRecord{
+			{LinkType: NotificationLinkTypePipeline, LinkID: "github.com/other/repo", PipelineDetail: &PipelineLinkDetail{Version: "1.0.0"}, Notifications: []Notification{{Type: NotificationTypeVulnerability, Level: NotificationLevelCritical}}},
+		}
+
+		// act
+		promotions := policy.Evaluate(pipeline, "1.0.0", nil, records, time.Date(2021, 4, 17, 12, 0, 0, 0, time.UTC))
+
+		if !assert.Equal(t, 1, len(promotions)) {
+			return
+		}
+		assert.True(t, promotions[0].Allowed)
+	})
+
+	t.Run("BlocksPromotionWhileReleaseIsRunningOrTargetIsMissing", func(t *testing.T) {
+
+		pipeline := Pipeline{
+			RepoSource: "github.com",
+			RepoOwner:  "estafette",
+			RepoName:   "estafette-ci-api",
+			ReleaseTargets: []ReleaseTarget{
+				{Name: "development"},
+				{Name: "staging"},
+				{Name: "production", Actions: []manifest.EstafetteReleaseAction{{Name: "deploy-canary"}, {Name: "deploy-stable"}}},
+			},
+		}
+		policy := ReleasePromotionPolicy{Stages: []*ReleasePromotionStage{{Target: "development"}, {Target: "tooling"}}}
+		releases := []*Release{
+			{Name: "development", ReleaseVersion: "1.0.4", ReleaseStatus: StatusRunning},
+		}
+
+		// act
+		promotions := policy.Evaluate(pipeline, "1.0.5", releases, nil, time.Now())
+
+		if !assert.Equal(t, 2, len(promotions)) {
+			return
+		}
+		assert.Equal(t, "Version 1.0.4 is being released to development", promotions[0].Gates[1].Reason)
+		assert.False(t, promotions[0].Allowed)
+		assert.Equal(t, "Pipeline has no release target tooling", promotions[1].Gates[0].Reason)
+	})
+}
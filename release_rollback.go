@@ -0,0 +1,87 @@
+package contracts
+
+import (
+	"fmt"
+	"time"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+// GetRollbackCandidate returns the release to roll back to when the latest release to the target and action failed or misbehaves: the most recent succeeded release before it with a different version that isn't newer
+func GetRollbackCandidate(releases []*Release, target, action string) (*Release, error) {
+
+	var latestRelease *Release
+	for _, r := range releases {
+		if r == nil || r.Name != target || r.Action != action || r.InsertedAt == nil {
+			continue
+		}
+		if latestRelease == nil || r.InsertedAt.After(*latestRelease.InsertedAt) {
+			latestRelease = r
+		}
+	}
+	if latestRelease == nil {
+		return nil, fmt.Errorf("There are no releases to %v to roll back", getReleaseTargetName(target, action))
+	}
+
+	var candidate *Release
+	for _, r := range releases {
+		if r == nil || r.Name != target || r.Action != action || r.InsertedAt == nil || r.ReleaseStatus != StatusSucceeded {
+			continue
+		}
+		if !r.InsertedAt.Before(*latestRelease.InsertedAt) || r.ReleaseVersion == latestRelease.ReleaseVersion || CompareVersions(r.ReleaseVersion, latestRelease.ReleaseVersion) > 0 {
+			continue
+		}
+		if candidate == nil || r.InsertedAt.After(*candidate.InsertedAt) {
+			candidate = r
+		}
+	}
+	if candidate == nil {
+		return nil, fmt.Errorf("There is no succeeded release to %v before version %v to roll back to", getReleaseTargetName(target, action), latestRelease.ReleaseVersion)
+	}
+
+	return candidate, nil
+}
+
+// NewRollbackRelease returns a pending release of the candidate's version to the same target and action, manually triggered by triggeredBy
+func NewRollbackRelease(candidate Release, triggeredBy string, at time.Time) Release {
+	return Release{
+		Name:           candidate.Name,
+		Action:         candidate.Action,
+		RepoSource:     candidate.RepoSource,
+		RepoOwner:      candidate.RepoOwner,
+		RepoName:       candidate.RepoName,
+		ReleaseVersion: candidate.ReleaseVersion,
+		ReleaseStatus:  StatusPending,
+		Events: []manifest.EstafetteEvent{
+			{
+				Fired: true,
+				Manual: &manifest.EstafetteManualEvent{
+					UserID: triggeredBy,
+				},
+			},
+		},
+		InsertedAt:    &at,
+		Groups:        candidate.Groups,
+		Organizations: candidate.Organizations,
+	}
+}
+
+// PlanRollback returns a release rolling back the target and action to the rollback candidate, awaiting approval if the approval policies require it
+func PlanRollback(releases []*Release, policies []*ReleaseApprovalPolicy, target, action, triggeredBy string, at time.Time) (*Release, error) {
+	candidate, err := GetRollbackCandidate(releases, target, action)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := NewRollbackRelease(*candidate, triggeredBy, at)
+	rollback.ReleaseStatus = EvaluateReleaseApprovalPolicies(policies, rollback, at).GetReleaseStatus()
+
+	return &rollback, nil
+}
+
+func getReleaseTargetName(target, action string) string {
+	if action != "" {
+		return fmt.Sprintf("%v/%v", target, action)
+	}
+	return target
+}
@@ -0,0 +1,120 @@
+package contracts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRollbackCandidate(t *testing.T) {
+	t.Run("ReturnsMostRecentSucceededReleaseWithOlderVersionForSameAction", func(t *testing.T) {
+
+		day1 := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+		day3 := time.Date(2021, 4, 3, 12, 0, 0, 0, time.UTC)
+		day4 := time.Date(2021, 4, 4, 12, 0, 0, 0, time.UTC)
+		day5 := time.Date(2021, 4, 5, 12, 0, 0, 0, time.UTC)
+		day6 := time.Date(2021, 4, 6, 12, 0, 0, 0, time.UTC)
+		day7 := time.Date(2021, 4, 7, 12, 0, 0, 0, time.UTC)
+		day8 := time.Date(2021, 4, 8, 12, 0, 0, 0, time.UTC)
+		day9 := time.Date(2021, 4, 9, 12, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "1", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.1", ReleaseStatus: StatusSucceeded, InsertedAt: &day1},
+			{ID: "2", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.3", ReleaseStatus: StatusSucceeded, InsertedAt: &day3},
+			{ID: "3", Name: "production", Action: "deploy-canary", ReleaseVersion: "1.0.4", ReleaseStatus: StatusSucceeded, InsertedAt: &day4},
+			{ID: "4", Name: "staging", ReleaseVersion: "1.0.5", ReleaseStatus: StatusSucceeded, InsertedAt: &day5},
+			{ID: "5", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.4", ReleaseStatus: StatusSucceeded, InsertedAt: &day6},
+			{ID: "6", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.10", ReleaseStatus: StatusSucceeded, InsertedAt: &day7},
+			{ID: "7", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.5", ReleaseStatus: StatusFailed, InsertedAt: &day8},
+			{ID: "8", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.5", ReleaseStatus: StatusFailed, InsertedAt: &day9},
+		}
+
+		// act
+		candidate, err := GetRollbackCandidate(releases, "production", "deploy-stable")
+
+		if !assert.Nil(t, err) || !assert.NotNil(t, candidate) {
+			return
+		}
+		assert.Equal(t, "5", candidate.ID)
+		assert.Equal(t, "1.0.4", candidate.ReleaseVersion)
+	})
+
+	t.Run("ReturnsErrorIfThereIsNoEarlierSucceededRelease", func(t *testing.T) {
+
+		day1 := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+		day4 := time.Date(2021, 4, 4, 12, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "1", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.1", ReleaseStatus: StatusSucceeded, InsertedAt: &day1},
+			{ID: "3", Name: "production", Action: "deploy-canary", ReleaseVersion: "1.0.4", ReleaseStatus: StatusSucceeded, InsertedAt: &day4},
+		}
+
+		// act
+		_, err := GetRollbackCandidate(releases, "production", "deploy-canary")
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsErrorIfThereAreNoReleasesForTarget", func(t *testing.T) {
+
+		day1 := time.Date(2021, 4, 1, 12, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "1", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.1", ReleaseStatus: StatusSucceeded, InsertedAt: &day1},
+		}
+
+		// act
+		_, err := GetRollbackCandidate(releases, "development", "")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestPlanRollback(t *testing.T) {
+	t.Run("ReturnsPendingReleaseOfCandidateVersionTriggeredByUser", func(t *testing.T) {
+
+		day6 := time.Date(2021, 4, 6, 12, 0, 0, 0, time.UTC)
+		day8 := time.Date(2021, 4, 8, 12, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "5", Name: "production", Action: "deploy-stable", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", ReleaseVersion: "1.0.4", ReleaseStatus: StatusSucceeded, InsertedAt: &day6, Groups: []*Group{{Name: "estafette"}}},
+			{ID: "7", Name: "production", Action: "deploy-stable", RepoSource: "github.com", RepoOwner: "estafette", RepoName: "estafette-ci-api", ReleaseVersion: "1.0.5", ReleaseStatus: StatusFailed, InsertedAt: &day8, Groups: []*Group{{Name: "estafette"}}},
+		}
+		at := time.Date(2021, 4, 9, 13, 0, 0, 0, time.UTC)
+
+		// act
+		rollback, err := PlanRollback(releases, nil, "production", "deploy-stable", "me@estafette.io", at)
+
+		if !assert.Nil(t, err) || !assert.NotNil(t, rollback) {
+			return
+		}
+		assert.Equal(t, "", rollback.ID)
+		assert.Equal(t, "production", rollback.Name)
+		assert.Equal(t, "deploy-stable", rollback.Action)
+		assert.Equal(t, "github.com/estafette/estafette-ci-api", rollback.GetFullRepoPath())
+		assert.Equal(t, "1.0.4", rollback.ReleaseVersion)
+		assert.Equal(t, StatusPending, rollback.ReleaseStatus)
+		assert.Equal(t, at, *rollback.InsertedAt)
+		assert.Equal(t, []string{"me@estafette.io"}, GetReleaseTriggerers(*rollback))
+		assert.Equal(t, "estafette", rollback.Groups[0].Name)
+	})
+
+	t.Run("ReturnsReleaseAwaitingApprovalIfTargetRequiresApproval", func(t *testing.T) {
+
+		day6 := time.Date(2021, 4, 6, 12, 0, 0, 0, time.UTC)
+		day8 := time.Date(2021, 4, 8, 12, 0, 0, 0, time.UTC)
+		releases := []*Release{
+			{ID: "5", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.4", ReleaseStatus: StatusSucceeded, InsertedAt: &day6},
+			{ID: "7", Name: "production", Action: "deploy-stable", ReleaseVersion: "1.0.5", ReleaseStatus: StatusFailed, InsertedAt: &day8},
+		}
+		policies := []*ReleaseApprovalPolicy{
+			{Name: "four-eyes", Targets: []string{"production"}},
+		}
+		at := time.Date(2021, 4, 9, 13, 0, 0, 0, time.UTC)
+
+		// act
+		rollback, err := PlanRollback(releases, policies, "production", "deploy-stable", "me@estafette.io", at)
+
+		if !assert.Nil(t, err) || !assert.NotNil(t, rollback) {
+			return
+		}
+		assert.Equal(t, StatusAwaitingApproval, rollback.ReleaseStatus)
+	})
+}
@@ -0,0 +1,875 @@
+// Code generated by schemagen from the estafette-ci-contracts go types; DO NOT EDIT.
+
+/** Bot represents a bot execution */
+export interface Bot {
+  name: string;
+  id?: string;
+  repoSource?: string;
+  repoOwner?: string;
+  repoName?: string;
+  botStatus?: Status;
+  triggerEvents?: EstafetteEvent[];
+  /** Time in RFC 3339 format */
+  insertedAt?: string;
+  /** Time in RFC 3339 format */
+  startedAt?: string;
+  /** Time in RFC 3339 format */
+  updatedAt?: string;
+  /** Duration in nanoseconds */
+  duration?: number;
+  /** Duration in nanoseconds */
+  pendingDuration?: number;
+  extraInfo?: BotExtraInfo;
+  groups?: Group[];
+  organizations?: Organization[];
+}
+
+/** BotExtraInfo contains extra information like aggregates over the last x releases */
+export interface BotExtraInfo {
+  /** Duration in nanoseconds */
+  medianPendingDuration: number;
+  /** Duration in nanoseconds */
+  medianDuration: number;
+  durationStatistics?: DurationStatistics;
+  pendingDurationStatistics?: DurationStatistics;
+}
+
+/** Build represents a specific build, including version number, repo, branch, revision, labels and manifest */
+export interface Build {
+  id: string;
+  repoSource: string;
+  repoOwner: string;
+  repoName: string;
+  repoBranch: string;
+  repoRevision: string;
+  buildVersion?: string;
+  buildStatus?: Status;
+  labels?: Label[];
+  releaseTargets?: ReleaseTarget[];
+  manifest?: string;
+  manifestWithDefaults?: string;
+  commits?: GitCommit[];
+  triggers?: EstafetteTrigger[];
+  triggerEvents?: EstafetteEvent[];
+  /** Time in RFC 3339 format */
+  insertedAt: string;
+  /** Time in RFC 3339 format */
+  startedAt?: string;
+  /** Time in RFC 3339 format */
+  updatedAt: string;
+  /** Duration in nanoseconds */
+  duration: number;
+  /** Duration in nanoseconds */
+  pendingDuration?: number;
+  groups?: Group[];
+  organizations?: Organization[];
+  matrixKey?: string;
+  matrix?: { [key: string]: string };
+}
+
+export type BuildEventType = "updateStatus" | "clean";
+
+/** BuildLog represents a build log for a specific revision */
+export interface BuildLog {
+  id?: string;
+  repoSource: string;
+  repoOwner: string;
+  repoName: string;
+  repoBranch: string;
+  repoRevision: string;
+  buildID: string;
+  steps: BuildLogStep[] | null;
+  /** Time in RFC 3339 format */
+  insertedAt: string;
+}
+
+/** BuildLogLine has low level log information */
+export interface BuildLogLine {
+  line?: number;
+  /** Time in RFC 3339 format */
+  timestamp: string;
+  streamType: string;
+  text: string;
+}
+
+/** BuildLogStep represents the logs for a single step of a pipeline */
+export interface BuildLogStep {
+  step: string;
+  depth?: number;
+  image: BuildLogStepDockerImage | null;
+  runIndex?: number;
+  /** Duration in nanoseconds */
+  duration: number;
+  logLines: BuildLogLine[] | null;
+  exitCode: number;
+  status: LogStatus | "";
+  autoInjected?: boolean;
+  nestedSteps?: BuildLogStep[];
+  services?: BuildLogStep[];
+  cacheKey?: string;
+  cacheHit?: boolean;
+}
+
+/** BuildLogStepDockerImage represents info about the docker image used for a step */
+export interface BuildLogStepDockerImage {
+  name: string;
+  tag: string;
+  isPulled: boolean;
+  imageSize: number;
+  /** Duration in nanoseconds */
+  pullDuration: number;
+  error?: string;
+  isTrusted?: boolean;
+  hasInjectedCredentials?: boolean;
+}
+
+/** BuilderConfig parameterizes a build/release job */
+export interface BuilderConfig {
+  apiVersion?: string;
+  jobType?: JobType;
+  build?: Build;
+  release?: Release;
+  bot?: Bot;
+  git?: GitConfig;
+  version?: VersionConfig;
+  track?: string;
+  dockerConfig?: DockerConfig;
+  manifest?: EstafetteManifest;
+  manifestPreferences?: EstafetteManifestPreferences;
+  jobName?: string;
+  triggerEvents?: EstafetteEvent[];
+  ciServer?: CIServerConfig;
+  stages?: EstafetteStage[];
+  credentials?: CredentialConfig[];
+  trustedImages?: TrustedImageConfig[];
+}
+
+export type BuilderType = "docker" | "kubernetes";
+
+/** CIServerConfig has a number of config items related to communication or linking to the CI server */
+export interface CIServerConfig {
+  baseUrl: string;
+  builderEventsUrl: string;
+  postLogsUrl: string;
+  cancelJobUrl: string;
+  jwt: string;
+  /** Time in RFC 3339 format */
+  jwtExpiry: string;
+}
+
+/** CatalogEntity represents any entity stored in the catalog tree */
+export interface CatalogEntity {
+  id?: string;
+  parentKey?: string;
+  parentValue?: string;
+  key?: string;
+  value?: string;
+  linkedPipeline?: string;
+  labels?: Label[];
+  metadata?: { [key: string]: unknown };
+  /** Time in RFC 3339 format */
+  insertedAt?: string;
+  /** Time in RFC 3339 format */
+  updatedAt?: string;
+}
+
+export interface ContainerLinkDetail {
+  tag?: string;
+  publicImage?: boolean;
+}
+
+/** CredentialConfig is used to store credentials for every type of authenticated service you can use from docker registries, to kubernetes engine to, github apis, bitbucket; in combination with trusted images access to these centrally stored credentials can be limited */
+export interface CredentialConfig {
+  name: string;
+  type: string;
+  allowedPipelines?: string;
+  allowedTrustedImages?: string;
+  allowedBranches?: string;
+  additionalProperties?: { [key: string]: unknown };
+}
+
+/** DeploymentMetrics contains the DORA metrics for a single release target in a period */
+export interface DeploymentMetrics {
+  /** Time in RFC 3339 format */
+  since: string;
+  /** Time in RFC 3339 format */
+  until: string;
+  deployments: number;
+  deploymentsPerDay: number;
+  /** Duration in nanoseconds */
+  medianLeadTime?: number;
+  changeFailureRate?: number;
+  /** Duration in nanoseconds */
+  meanTimeToRestore?: number;
+}
+
+/** DockerConfig has configuration to configure docker in estafette-ci-builder */
+export interface DockerConfig {
+  runType?: DockerRunType;
+  mtu?: number;
+  bip?: string;
+  networks?: DockerNetworkConfig[];
+  registryMirror?: string;
+}
+
+/** DockerNetworkConfig has settings for creating a user defined docker network to make service containers accessible by name from other containers */
+export interface DockerNetworkConfig {
+  name: string;
+  driver: string;
+  subnet: string;
+  gateway: string;
+  durable: boolean;
+}
+
+export type DockerRunType = "dind" | "dod";
+
+/** DurationStatistics contains percentiles, mean, standard deviation and trend over a series of durations */
+export interface DurationStatistics {
+  count: number;
+  /** Duration in nanoseconds */
+  p50: number;
+  /** Duration in nanoseconds */
+  p90: number;
+  /** Duration in nanoseconds */
+  p99: number;
+  /** Duration in nanoseconds */
+  mean: number;
+  /** Duration in nanoseconds */
+  standardDeviation: number;
+  /** Duration in nanoseconds */
+  trendSlope: number;
+}
+
+/** EstafetteBitbucketEvent fires for bitbucket events */
+export interface EstafetteBitbucketEvent {
+  event?: string;
+  repository?: string;
+  hookUUID?: string;
+  requestUUID?: string;
+  attemptNumber?: string;
+  payload?: string;
+}
+
+/** EstafetteBitbucketTrigger fires for bitbucket events */
+export interface EstafetteBitbucketTrigger {
+  events?: string[];
+  repository?: string;
+}
+
+/** EstafetteBot allows to respond to any event coming from one of the integrations */
+export interface EstafetteBot {
+  Name: string;
+  Builder: EstafetteBuilder | null;
+  CloneRepository?: boolean;
+  Triggers?: EstafetteTrigger[];
+  Stages?: EstafetteStage[];
+}
+
+/** EstafetteBuilder contains configuration for the ci-builder component */
+export interface EstafetteBuilder {
+  Track: string;
+  OperatingSystem: OperatingSystem | "";
+  StorageMedium: StorageMedium;
+  BuilderType: BuilderType | "";
+}
+
+export interface EstafetteCiBuilderEvent {
+  buildEventType?: BuildEventType;
+  jobType?: JobType;
+  job_name: string;
+  pod_name?: string;
+  build?: Build;
+  release?: Release;
+  bot?: Bot;
+  git?: GitConfig;
+}
+
+/** EstafetteCronEvent fires at intervals specified by the cron expression */
+export interface EstafetteCronEvent {
+  /** Time in RFC 3339 format */
+  time?: string;
+}
+
+/** EstafetteCronTrigger fires at intervals specified by the cron schedule */
+export interface EstafetteCronTrigger {
+  schedule?: string;
+}
+
+/** EstafetteCustomVersion represents a custom version using a template */
+export interface EstafetteCustomVersion {
+  LabelTemplate: string;
+}
+
+/** EstafetteDockerEvent fires for docker image changes */
+export interface EstafetteDockerEvent {
+  event?: string;
+  image?: string;
+  tag?: string;
+}
+
+/** EstafetteDockerTrigger fires for docker image changes and applies filtering to limit when this results in an action */
+export interface EstafetteDockerTrigger {
+  event?: string;
+  image?: string;
+  tag?: string;
+}
+
+/** EstafetteEvent is a container for any trigger event */
+export interface EstafetteEvent {
+  name?: string;
+  fired?: boolean;
+  pipeline?: EstafettePipelineEvent;
+  release?: EstafetteReleaseEvent;
+  git?: EstafetteGitEvent;
+  docker?: EstafetteDockerEvent;
+  cron?: EstafetteCronEvent;
+  pubsub?: EstafettePubSubEvent;
+  github?: EstafetteGithubEvent;
+  bitbucket?: EstafetteBitbucketEvent;
+  manual?: EstafetteManualEvent;
+}
+
+/** EstafetteGitEvent fires for git repository changes */
+export interface EstafetteGitEvent {
+  event?: string;
+  repository?: string;
+  branch?: string;
+}
+
+/** EstafetteGitTrigger fires for git repository changes and applies filtering to limit when this results in an action */
+export interface EstafetteGitTrigger {
+  event?: string;
+  repository?: string;
+  branch?: string;
+}
+
+/** EstafetteGithubEvent fires for github events */
+export interface EstafetteGithubEvent {
+  event?: string;
+  repository?: string;
+  delivery?: string;
+  payload?: string;
+}
+
+/** EstafetteGithubTrigger fires for github events */
+export interface EstafetteGithubTrigger {
+  events?: string[];
+  repository?: string;
+}
+
+/** EstafetteManifest is the object that the .estafette.yaml deserializes to */
+export interface EstafetteManifest {
+  Archived: boolean;
+  Builder: EstafetteBuilder;
+  Labels: { [key: string]: string } | null;
+  Version: EstafetteVersion;
+  GlobalEnvVars: { [key: string]: string } | null;
+  Triggers: EstafetteTrigger[] | null;
+  Stages: EstafetteStage[] | null;
+  Releases: EstafetteRelease[] | null;
+  ReleaseTemplates: EstafetteReleaseTemplate[] | null;
+  Bots: EstafetteBot[] | null;
+}
+
+/** EstafetteManifestPreferences is used to configure validation rules for the manifest */
+export interface EstafetteManifestPreferences {
+  labelRegexes?: { [key: string]: string };
+  builderOperatingSystems?: OperatingSystem[];
+  builderTracksPerOperatingSystem?: { [key: string]: string[] };
+  defaultBranch?: string;
+}
+
+/** EstafetteManualEvent fires when a user manually triggers a build or release */
+export interface EstafetteManualEvent {
+  userID?: string;
+}
+
+/** EstafettePipelineEvent fires for pipeline changes */
+export interface EstafettePipelineEvent {
+  buildVersion?: string;
+  repoSource?: string;
+  repoOwner?: string;
+  repoName?: string;
+  repoBranch?: string;
+  status?: string;
+  event?: string;
+}
+
+/** EstafettePipelineTrigger fires for pipeline changes and applies filtering to limit when this results in an action */
+export interface EstafettePipelineTrigger {
+  event?: string;
+  status?: string;
+  name?: string;
+  branch?: string;
+}
+
+/** EstafettePubSubEvent fires when a subscribed pubsub topic receives an event */
+export interface EstafettePubSubEvent {
+  project?: string;
+  topic?: string;
+  message?: PubsubMessage;
+}
+
+/** EstafettePubSubTrigger fires for pubsub events in a certain project and topic */
+export interface EstafettePubSubTrigger {
+  project?: string;
+  topic?: string;
+}
+
+/** EstafetteRelease represents a release target that in itself contains one or multiple stages */
+export interface EstafetteRelease {
+  Name: string;
+  Builder: EstafetteBuilder | null;
+  CloneRepository?: boolean;
+  Actions?: EstafetteReleaseAction[];
+  Triggers?: EstafetteTrigger[];
+  Stages?: EstafetteStage[];
+  Template: string;
+}
+
+/** EstafetteReleaseAction represents an action on a release target that controls what happens by running the release stage */
+export interface EstafetteReleaseAction {
+  name: string;
+  hideBadge?: boolean;
+}
+
+/** EstafetteReleaseEvent fires for pipeline releases */
+export interface EstafetteReleaseEvent {
+  releaseVersion?: string;
+  repoSource?: string;
+  repoOwner?: string;
+  repoName?: string;
+  target?: string;
+  status?: string;
+  event?: string;
+}
+
+/** EstafetteReleaseTemplate represents a template for a release target */
+export interface EstafetteReleaseTemplate {
+  Name: string;
+  Builder: EstafetteBuilder | null;
+  CloneRepository?: boolean;
+  Actions?: EstafetteReleaseAction[];
+  Triggers?: EstafetteTrigger[];
+  Stages: EstafetteStage[] | null;
+}
+
+/** EstafetteReleaseTrigger fires for pipeline releases and applies filtering to limit when this results in an action */
+export interface EstafetteReleaseTrigger {
+  event?: string;
+  status?: string;
+  name?: string;
+  target?: string;
+}
+
+/** EstafetteSemverVersion represents semantic versioning (http://semver.org/) */
+export interface EstafetteSemverVersion {
+  Major: number;
+  Minor: number;
+  Patch: string;
+  LabelTemplate: string;
+  ReleaseBranch: string | string[];
+}
+
+/** EstafetteService represents a service container to run during a single or multiple stages */
+export interface EstafetteService {
+  Name: string;
+  ContainerImage: string;
+  Shell: string;
+  Commands: string[] | null;
+  RunCommandsInForeground: boolean;
+  MultiStage: boolean | null;
+  When: string;
+  EnvVars: { [key: string]: string } | null;
+  Readiness: ReadinessProbe | null;
+  ReadinessProbe: ReadinessProbe | null;
+  CustomProperties: { [key: string]: unknown } | null;
+}
+
+/** EstafetteStage represents a stage of a build pipeline or release */
+export interface EstafetteStage {
+  Name?: string;
+  ContainerImage?: string;
+  Shell?: string;
+  WorkingDirectory?: string;
+  Commands?: string[];
+  RunCommandsInForeground?: boolean;
+  When?: string;
+  EnvVars?: { [key: string]: string };
+  AutoInjected?: boolean;
+  ParallelStages?: EstafetteStage[];
+  Services?: EstafetteService[];
+  CustomProperties?: { [key: string]: unknown };
+}
+
+/** EstafetteTrigger represents a trigger of any supported type and what action to take if the trigger fired */
+export interface EstafetteTrigger {
+  name?: string;
+  pipeline?: EstafettePipelineTrigger;
+  release?: EstafetteReleaseTrigger;
+  git?: EstafetteGitTrigger;
+  docker?: EstafetteDockerTrigger;
+  cron?: EstafetteCronTrigger;
+  pubsub?: EstafettePubSubTrigger;
+  github?: EstafetteGithubTrigger;
+  bitbucket?: EstafetteBitbucketTrigger;
+  builds?: EstafetteTriggerBuildAction;
+  releases?: EstafetteTriggerReleaseAction;
+  runs?: EstafetteTriggerBotAction;
+}
+
+export interface EstafetteTriggerBotAction {
+  bot?: string;
+  branch?: string;
+}
+
+/** EstafetteTriggerBuildAction determines what builds when the trigger fires */
+export interface EstafetteTriggerBuildAction {
+  branch?: string;
+}
+
+/** EstafetteTriggerReleaseAction determines what releases when the trigger fires */
+export interface EstafetteTriggerReleaseAction {
+  target?: string;
+  action?: string;
+  version?: string;
+}
+
+/** EstafetteVersion is the object that determines how version numbers are generated */
+export interface EstafetteVersion {
+  SemVer?: EstafetteSemverVersion;
+  Custom?: EstafetteCustomVersion;
+}
+
+export interface ExecProbe {
+  Command: string[] | null;
+}
+
+/** GitAuthor represents the author of a commmit */
+export interface GitAuthor {
+  email: string;
+  name: string;
+  username: string;
+}
+
+/** GitCommit represents a commit summary */
+export interface GitCommit {
+  message: string;
+  author: GitAuthor;
+}
+
+/** GitConfig contains all information for cloning the git repository for building/releasing a specific version */
+export interface GitConfig {
+  repoSource: string;
+  repoOwner: string;
+  repoName: string;
+  repoBranch: string;
+  repoRevision: string;
+}
+
+/** Group represents a group of users as configured in different systems */
+export interface Group {
+  id?: string;
+  active?: boolean;
+  name?: string;
+  description?: string;
+  identities?: GroupIdentity[];
+  organizations?: Organization[];
+  roles?: string[];
+}
+
+/** GroupIdentity represents the various identities a group can have in different systems */
+export interface GroupIdentity {
+  provider?: string;
+  id?: string;
+  name?: string;
+}
+
+export interface HttpGetProbe {
+  Path: string;
+  Port: number;
+  Host: string;
+  Scheme: string;
+}
+
+export type JobType = "build" | "release" | "bot";
+
+/** Label represents a key/value pair as set in a build manifest */
+export interface Label {
+  key: string;
+  value: string;
+}
+
+export type LogStatus = "UNKNOWN" | "SUCCEEDED" | "FAILED" | "SKIPPED" | "CANCELED" | "PENDING" | "RUNNING";
+
+export type LogType = "stage" | "service";
+
+export interface Notification {
+  type?: NotificationType;
+  level?: NotificationLevel;
+  message?: string;
+  vulnerability?: VulnerabilityDetail;
+}
+
+export type NotificationLevel = "critical" | "high" | "medium" | "low";
+
+export type NotificationLinkType = "pipeline" | "container";
+
+export interface NotificationRecord {
+  id?: string;
+  linkType?: NotificationLinkType;
+  linkID?: string;
+  pipelineDetail?: PipelineLinkDetail;
+  containerDetail?: ContainerLinkDetail;
+  source?: string;
+  notifications?: Notification[];
+  /** Time in RFC 3339 format */
+  insertedAt?: string;
+  groups?: Group[];
+  organizations?: Organization[];
+}
+
+export type NotificationType = "vulnerability" | "warning";
+
+export type OperatingSystem = "linux" | "windows";
+
+/** Organization represents an organization that uses a multi-tenancy installation */
+export interface Organization {
+  id?: string;
+  active?: boolean;
+  name?: string;
+  identities?: OrganizationIdentity[];
+  roles?: string[];
+}
+
+/** OrganizationIdentity represents the various identities an organization can have in different systems */
+export interface OrganizationIdentity {
+  provider?: string;
+  id?: string;
+  name?: string;
+}
+
+/** Pipeline represents a pipeline with the latest build info, including version number, repo, branch, revision, labels and manifest */
+export interface Pipeline {
+  id: string;
+  repoSource: string;
+  repoOwner: string;
+  repoName: string;
+  repoBranch: string;
+  repoRevision: string;
+  buildVersion?: string;
+  buildStatus?: Status;
+  labels?: Label[];
+  releaseTargets?: ReleaseTarget[];
+  manifest?: string;
+  manifestWithDefaults?: string;
+  commits?: GitCommit[];
+  triggers?: EstafetteTrigger[];
+  triggerEvents?: EstafetteEvent[];
+  archived?: boolean;
+  /** Time in RFC 3339 format */
+  insertedAt: string;
+  /** Time in RFC 3339 format */
+  startedAt?: string;
+  /** Time in RFC 3339 format */
+  updatedAt: string;
+  /** Duration in nanoseconds */
+  duration: number;
+  /** Duration in nanoseconds */
+  pendingDuration?: number;
+  /** Time in RFC 3339 format */
+  lastUpdatedAt: string;
+  recentCommitters?: string[];
+  recentReleasers?: string[];
+  extraInfo?: PipelineExtraInfo;
+  groups?: Group[];
+  organizations?: Organization[];
+}
+
+/** PipelineExtraInfo contains extra information like aggregates over the last x builds */
+export interface PipelineExtraInfo {
+  /** Duration in nanoseconds */
+  medianPendingDuration: number;
+  /** Duration in nanoseconds */
+  medianDuration: number;
+  metrics?: PipelineMetrics;
+  durationStatistics?: DurationStatistics;
+  pendingDurationStatistics?: DurationStatistics;
+}
+
+export interface PipelineLinkDetail {
+  branch?: string;
+  revision: string;
+  version?: string;
+  status?: Status;
+}
+
+/** PipelineMetrics contains health and DORA metrics over the builds and releases of a pipeline in a period */
+export interface PipelineMetrics {
+  /** Time in RFC 3339 format */
+  since: string;
+  /** Time in RFC 3339 format */
+  until: string;
+  buildSuccessRate?: number;
+  flakiness?: number;
+  targets?: { [key: string]: DeploymentMetrics };
+}
+
+/** PubsubMessage is a container for a pubsub push message */
+export interface PubsubMessage {
+  attributes?: { [key: string]: string };
+  data?: string;
+  messageId?: string;
+  /** Time in RFC 3339 format */
+  publishTime?: string;
+  orderingKey?: string;
+}
+
+/** ReadinessProbe defines an http readiness probe */
+export interface ReadinessProbe {
+  HttpGet: HttpGetProbe | null;
+  Exec: ExecProbe | null;
+  TimeoutSeconds: number;
+  Path: string;
+  Port: number;
+  Protocol: string;
+  Hostname: string;
+}
+
+/** Release represents a release of a pipeline */
+export interface Release {
+  name: string;
+  action?: string;
+  id?: string;
+  repoSource?: string;
+  repoOwner?: string;
+  repoName?: string;
+  releaseVersion?: string;
+  releaseStatus?: Status;
+  triggerEvents?: EstafetteEvent[];
+  /** Time in RFC 3339 format */
+  insertedAt?: string;
+  /** Time in RFC 3339 format */
+  startedAt?: string;
+  /** Time in RFC 3339 format */
+  updatedAt?: string;
+  /** Duration in nanoseconds */
+  duration?: number;
+  /** Duration in nanoseconds */
+  pendingDuration?: number;
+  extraInfo?: ReleaseExtraInfo;
+  approvals?: ReleaseApproval[];
+  groups?: Group[];
+  organizations?: Organization[];
+}
+
+/** ReleaseApproval records the decision of a user to approve or reject a release */
+export interface ReleaseApproval {
+  user: User | null;
+  decision: ReleaseApprovalDecision | "";
+  comment?: string;
+  /** Time in RFC 3339 format */
+  decidedAt: string;
+}
+
+export type ReleaseApprovalDecision = "approved" | "rejected";
+
+/** ReleaseExtraInfo contains extra information like aggregates over the last x releases */
+export interface ReleaseExtraInfo {
+  /** Duration in nanoseconds */
+  medianPendingDuration: number;
+  /** Duration in nanoseconds */
+  medianDuration: number;
+  metrics?: DeploymentMetrics;
+  durationStatistics?: DurationStatistics;
+  pendingDurationStatistics?: DurationStatistics;
+}
+
+/** ReleaseTarget contains the information to visualize and trigger release */
+export interface ReleaseTarget {
+  name: string;
+  actions?: EstafetteReleaseAction[];
+  activeReleases?: Release[];
+}
+
+export type Status = "pending" | "running" | "succeeded" | "failed" | "canceling" | "canceled" | "awaiting-approval";
+
+export type StorageMedium = "" | "memory";
+
+/** TailLogLine returns a log line for streaming logs to gui during a build */
+export interface TailLogLine {
+  step: string;
+  parentStage?: string;
+  type: LogType | "";
+  depth?: number;
+  runIndex?: number;
+  logLine?: BuildLogLine;
+  image?: BuildLogStepDockerImage;
+  /** Duration in nanoseconds */
+  duration?: number;
+  exitCode?: number;
+  status?: LogStatus;
+  autoInjected?: boolean;
+}
+
+/** TrustedImageConfig allows trusted images to run docker commands or receive specific credentials */
+export interface TrustedImageConfig {
+  path: string;
+  runPrivileged: boolean;
+  runDocker: boolean;
+  allowCommands: boolean;
+  allowNotifications: boolean;
+  injectedCredentialTypes?: string[];
+  allowedPipelines?: string;
+}
+
+/** User represents a user of Estafette */
+export interface User {
+  id?: string;
+  active?: boolean;
+  identities?: UserIdentity[];
+  groups?: Group[];
+  organizations?: Organization[];
+  roles?: string[];
+  preferences?: { [key: string]: unknown };
+  /** Time in RFC 3339 format */
+  firstVisit?: string;
+  /** Time in RFC 3339 format */
+  lastVisit?: string;
+  currentProvider?: string;
+  currentOrganization?: string;
+  name?: string;
+  email?: string;
+}
+
+/** UserIdentity represents the various identities a user can have in different systems */
+export interface UserIdentity {
+  provider?: string;
+  id?: string;
+  email?: string;
+  name?: string;
+  avatar?: string;
+}
+
+/** VersionConfig contains all information regarding the version number to build or release */
+export interface VersionConfig {
+  version: string;
+  major?: number;
+  minor?: number;
+  patch?: string;
+  label?: string;
+  autoincrement?: number;
+  currentCounter?: number;
+  maxCounter?: number;
+  maxCounterCurrentBranch?: number;
+}
+
+/** VulnerabilityDetail describes a single vulnerability in a package found by a scanner */
+export interface VulnerabilityDetail {
+  id?: string;
+  package?: string;
+  installedVersion?: string;
+  fixedVersion?: string;
+  cvssScore?: number;
+  links?: string[];
+}
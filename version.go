@@ -0,0 +1,194 @@
+package contracts
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed estafette semver version like 1.2.3 or 1.2.3-feature-branch
+type Version struct {
+	Major    int
+	Minor    int
+	Patch    int
+	Label    string
+	Metadata string
+}
+
+var versionRegex = regexp.MustCompile(`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+// ParseVersion parses versions of format major.minor.patch with an optional -label and +metadata
+func ParseVersion(version string) (v Version, err error) {
+	matches := versionRegex.FindStringSubmatch(strings.TrimSpace(version))
+	if matches == nil {
+		return v, fmt.Errorf("Version %q is not of format major.minor.patch[-label]", version)
+	}
+
+	if v.Major, err = strconv.Atoi(matches[1]); err != nil {
+		return v, fmt.Errorf("Major of version %q is invalid: %w", version, err)
+	}
+	if v.Minor, err = strconv.Atoi(matches[2]); err != nil {
+		return v, fmt.Errorf("Minor of version %q is invalid: %w", version, err)
+	}
+	if v.Patch, err = strconv.Atoi(matches[3]); err != nil {
+		return v, fmt.Errorf("Patch of version %q is invalid: %w", version, err)
+	}
+	v.Label = matches[4]
+	v.Metadata = matches[5]
+
+	for _, identifier := range strings.Split(v.Label, ".") {
+		if v.Label != "" && identifier == "" {
+			return v, fmt.Errorf("Label of version %q has an empty identifier", version)
+		}
+	}
+
+	return v, nil
+}
+
+// NewVersionFromConfig returns the version for a VersionConfig, preferring its separate fields over the version string
+func NewVersionFromConfig(config VersionConfig) (v Version, err error) {
+	if config.Major == nil || config.Minor == nil {
+		return ParseVersion(config.Version)
+	}
+
+	v.Major = *config.Major
+	v.Minor = *config.Minor
+
+	switch {
+	case config.Patch != nil:
+		if v.Patch, err = strconv.Atoi(*config.Patch); err != nil {
+			return v, fmt.Errorf("Patch %q of version config is not a number: %w", *config.Patch, err)
+		}
+	case config.AutoIncrement != nil:
+		v.Patch = *config.AutoIncrement
+	default:
+		return ParseVersion(config.Version)
+	}
+
+	if config.Label != nil {
+		v.Label = *config.Label
+	}
+
+	return v, nil
+}
+
+// String returns the version as major.minor.patch with the label and metadata if set
+func (v Version) String() string {
+	version := fmt.Sprintf("%v.%v.%v", v.Major, v.Minor, v.Patch)
+	if v.Label != "" {
+		version += "-" + v.Label
+	}
+	if v.Metadata != "" {
+		version += "+" + v.Metadata
+	}
+
+	return version
+}
+
+// IsPrerelease returns true for versions with a label, which are built from non-release branches
+func (v Version) IsPrerelease() bool {
+	return v.Label != ""
+}
+
+// Compare returns -1, 0 or 1 following semver precedence
+func (v Version) Compare(other Version) int {
+	if c := compareOrdered(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareOrdered(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareOrdered(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+
+	switch {
+	case v.Label == other.Label:
+		return 0
+	case v.Label == "":
+		return 1
+	case other.Label == "":
+		return -1
+	}
+
+	identifiers := strings.Split(v.Label, ".")
+	otherIdentifiers := strings.Split(other.Label, ".")
+	for i := 0; i < len(identifiers) && i < len(otherIdentifiers); i++ {
+		if c := compareLabelIdentifiers(identifiers[i], otherIdentifiers[i]); c != 0 {
+			return c
+		}
+	}
+
+	return compareOrdered(len(identifiers), len(otherIdentifiers))
+}
+
+// LessThan returns true if v has lower precedence than other
+func (v Version) LessThan(other Version) bool {
+	return v.Compare(other) < 0
+}
+
+// CompareVersions compares two version strings, sorting versions that can't be parsed before the ones that can
+func CompareVersions(a, b string) int {
+	va, errA := ParseVersion(a)
+	vb, errB := ParseVersion(b)
+
+	switch {
+	case errA == nil && errB == nil:
+		return va.Compare(vb)
+	case errA == nil:
+		return 1
+	case errB == nil:
+		return -1
+	}
+
+	return strings.Compare(a, b)
+}
+
+// SortVersions sorts version strings from lowest to highest
+func SortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return CompareVersions(versions[i], versions[j]) < 0
+	})
+}
+
+// SortBuildsByVersion sorts builds from highest to lowest version
+func SortBuildsByVersion(builds []*Build) {
+	sort.SliceStable(builds, func(i, j int) bool {
+		return CompareVersions(builds[i].BuildVersion, builds[j].BuildVersion) > 0
+	})
+}
+
+// GetLatestReleasePerTarget returns the release with the highest version for each release target name and action
+func GetLatestReleasePerTarget(releases []*Release) map[string]*Release {
+	latestReleases := map[string]*Release{}
+	for _, r := range releases {
+		if r == nil {
+			continue
+		}
+		key := getReleaseTargetName(r.Name, r.Action)
+		if latest, ok := latestReleases[key]; !ok || CompareVersions(r.ReleaseVersion, latest.ReleaseVersion) > 0 {
+			latestReleases[key] = r
+		}
+	}
+
+	return latestReleases
+}
+
+// compareLabelIdentifiers compares dot separated label parts like semver prerelease identifiers
+func compareLabelIdentifiers(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+
+	switch {
+	case errA == nil && errB == nil:
+		return compareOrdered(na, nb)
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
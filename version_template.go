@@ -0,0 +1,155 @@
+package contracts
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+)
+
+// VersionTemplate describes how a version is computed from placeholders like {{auto}}, {{counter}}, {{branch}} and {{revision}}
+type VersionTemplate struct {
+	// Custom renders the complete version, instead of major.minor.patch-label
+	Custom          string   `yaml:"custom,omitempty" json:"custom,omitempty"`
+	PatchTemplate   string   `yaml:"patch,omitempty" json:"patch,omitempty"`
+	LabelTemplate   string   `yaml:"labelTemplate,omitempty" json:"labelTemplate,omitempty"`
+	ReleaseBranches []string `yaml:"releaseBranch,omitempty" json:"releaseBranch,omitempty"`
+}
+
+var (
+	versionLabelInvalidCharactersRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+	versionLabelLeadingRegex           = regexp.MustCompile(`^[0-9-]+`)
+	versionTemplatePlaceholderRegex    = regexp.MustCompile(`{{\s*([^}\s]+)\s*}}`)
+)
+
+// NewVersionTemplate returns the version template for the version section of a manifest, so versions computed by ComputeVersion match the manifest's
+func NewVersionTemplate(version manifest.EstafetteVersion) VersionTemplate {
+	version.SetDefaults()
+
+	if version.Custom != nil {
+		return VersionTemplate{
+			Custom: version.Custom.LabelTemplate,
+		}
+	}
+
+	return VersionTemplate{
+		PatchTemplate:   version.SemVer.Patch,
+		LabelTemplate:   version.SemVer.LabelTemplate,
+		ReleaseBranches: version.SemVer.ReleaseBranch.Values,
+	}
+}
+
+// SetDefaults sets the patch template to {{auto}}, the label template to {{branch}} and the release branches to master and main
+func (vt *VersionTemplate) SetDefaults() {
+	if vt.Custom != "" {
+		return
+	}
+	if vt.PatchTemplate == "" {
+		vt.PatchTemplate = "{{auto}}"
+	}
+	if vt.LabelTemplate == "" {
+		vt.LabelTemplate = "{{branch}}"
+	}
+	if len(vt.ReleaseBranches) == 0 {
+		vt.ReleaseBranches = []string{"master", "main"}
+	}
+}
+
+// ComputeVersion returns the version for the counters in the version config and the branch and revision in the git config
+func ComputeVersion(config VersionConfig, git GitConfig, vt VersionTemplate) (string, error) {
+	vt.SetDefaults()
+
+	funcMap := getVersionTemplateFuncMap(config, git)
+
+	if vt.Custom != "" {
+		return renderVersionTemplate(vt.Custom, funcMap)
+	}
+
+	major := 0
+	if config.Major != nil {
+		major = *config.Major
+	}
+	minor := 0
+	if config.Minor != nil {
+		minor = *config.Minor
+	}
+
+	patch, err := renderVersionTemplate(vt.PatchTemplate, funcMap)
+	if err != nil {
+		return "", err
+	}
+
+	if containsString(vt.ReleaseBranches, git.RepoBranch) {
+		return fmt.Sprintf("%v.%v.%v", major, minor, patch), nil
+	}
+
+	label, err := renderVersionTemplate(vt.LabelTemplate, funcMap)
+	if err != nil {
+		return "", err
+	}
+	label = tidyVersionLabel(label, vt.LabelTemplate)
+	if label == "" {
+		return fmt.Sprintf("%v.%v.%v", major, minor, patch), nil
+	}
+
+	return fmt.Sprintf("%v.%v.%v-%v", major, minor, patch, label), nil
+}
+
+// getVersionTemplateFuncMap returns the placeholders available in version templates
+func getVersionTemplateFuncMap(config VersionConfig, git GitConfig) template.FuncMap {
+	auto := config.CurrentCounter
+	if config.AutoIncrement != nil {
+		auto = *config.AutoIncrement
+	}
+
+	return template.FuncMap{
+		"auto":                    func() string { return fmt.Sprint(auto) },
+		"counter":                 func() string { return fmt.Sprint(config.CurrentCounter) },
+		"maxcounter":              func() string { return fmt.Sprint(config.MaxCounter) },
+		"maxcountercurrentbranch": func() string { return fmt.Sprint(config.MaxCounterCurrentBranch) },
+		"branch":                  func() string { return git.RepoBranch },
+		"revision":                func() string { return git.RepoRevision },
+	}
+}
+
+// renderVersionTemplate uses html/template like the manifest package, so escaped characters end up in versions the same way
+func renderVersionTemplate(templateText string, funcMap template.FuncMap) (string, error) {
+	tmpl, err := template.New("version").Funcs(funcMap).Parse(templateText)
+	if err != nil {
+		return "", fmt.Errorf("Version template %q is invalid: %w", templateText, err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err = tmpl.Execute(buf, nil); err != nil {
+		return "", fmt.Errorf("Version template %q can't be rendered: %w", templateText, err)
+	}
+
+	return buf.String(), nil
+}
+
+// tidyVersionLabel makes the label usable as dns label of at most 63 characters starting with a letter
+func tidyVersionLabel(label, labelTemplate string) string {
+	// prefix a label starting with a digit with the name of the first placeholder in the template
+	if label != "" && label[0] >= '0' && label[0] <= '9' {
+		prefix := "label-"
+		if match := versionTemplatePlaceholderRegex.FindStringSubmatch(labelTemplate); len(match) > 1 {
+			prefix = match[1] + "-"
+		}
+		label = prefix + label
+	}
+
+	label = strings.ToLower(label)
+	label = versionLabelInvalidCharactersRegex.ReplaceAllString(label, "-")
+	label = strings.Replace(label, "--", "-", -1)
+	label = strings.Trim(label, "-")
+	label = versionLabelLeadingRegex.ReplaceAllString(label, "")
+
+	if len(label) > 63 {
+		label = label[:63]
+	}
+
+	return label
+}
@@ -0,0 +1,93 @@
+package contracts
+
+import (
+	"testing"
+
+	manifest "github.com/estafette/estafette-ci-manifest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeVersion(t *testing.T) {
+	t.Run("ReturnsVersionForBranch", func(t *testing.T) {
+
+		major := 1
+		minor := 2
+		config := VersionConfig{Major: &major, Minor: &minor, CurrentCounter: 15, MaxCounter: 20, MaxCounterCurrentBranch: 4}
+
+		testCases := []struct {
+			branch   string
+			template VersionTemplate
+			expected string
+		}{
+			{"main", VersionTemplate{}, "1.2.15"},
+			{"master", VersionTemplate{}, "1.2.15"},
+			{"feature/Add-Versioning", VersionTemplate{}, "1.2.15-feature-add-versioning"},
+			{"123-fix", VersionTemplate{}, "1.2.15-branch-123-fix"},
+			{"release", VersionTemplate{ReleaseBranches: []string{"release"}}, "1.2.15"},
+			{"main", VersionTemplate{ReleaseBranches: []string{"release"}}, "1.2.15-main"},
+			{"feature", VersionTemplate{PatchTemplate: "{{maxcounter}}"}, "1.2.20-feature"},
+			{"feature", VersionTemplate{PatchTemplate: "{{maxcounter}}", LabelTemplate: "{{branch}}-{{maxcountercurrentbranch}}"}, "1.2.20-feature-4"},
+			{"feature", VersionTemplate{LabelTemplate: "{{revision}}"}, "1.2.15-revision-5a2d5b2c"},
+			{"feature", VersionTemplate{Custom: "{{branch}}-{{revision}}"}, "feature-5a2d5b2c"},
+		}
+
+		for _, tc := range testCases {
+			git := GitConfig{RepoBranch: tc.branch, RepoRevision: "5a2d5b2c"}
+
+			// act
+			version, err := ComputeVersion(config, git, tc.template)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, version, tc.branch)
+		}
+	})
+
+	t.Run("ReturnsAutoIncrementForAutoIfSet", func(t *testing.T) {
+
+		autoIncrement := 7
+		config := VersionConfig{AutoIncrement: &autoIncrement, CurrentCounter: 15}
+
+		// act
+		version, err := ComputeVersion(config, GitConfig{RepoBranch: "main"}, VersionTemplate{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "0.0.7", version)
+	})
+
+	t.Run("ReturnsErrorForUnknownPlaceholder", func(t *testing.T) {
+
+		// act
+		_, err := ComputeVersion(VersionConfig{}, GitConfig{RepoBranch: "main"}, VersionTemplate{PatchTemplate: "{{build}}"})
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("ReturnsSameVersionAsManifest", func(t *testing.T) {
+
+		testCases := []manifest.EstafetteVersion{
+			{SemVer: &manifest.EstafetteSemverVersion{Major: 1, Minor: 3}},
+			{SemVer: &manifest.EstafetteSemverVersion{LabelTemplate: "{{branch}}-{{auto}}", ReleaseBranch: manifest.StringOrStringArray{Values: []string{"production"}}}},
+			{Custom: &manifest.EstafetteCustomVersion{}},
+			{Custom: &manifest.EstafetteCustomVersion{LabelTemplate: "{{auto}}-{{branch}}"}},
+		}
+
+		for _, tc := range testCases {
+			tc.SetDefaults()
+
+			for _, branch := range []string{"main", "production", "feature/Versioning", "42-fix", "feature/a+b", "fix/it's-a&b"} {
+				config := VersionConfig{CurrentCounter: 31}
+				if tc.SemVer != nil {
+					config.Major = &tc.SemVer.Major
+					config.Minor = &tc.SemVer.Minor
+				}
+				git := GitConfig{RepoBranch: branch, RepoRevision: "5a2d5b2c"}
+
+				// act
+				version, err := ComputeVersion(config, git, NewVersionTemplate(tc))
+
+				assert.Nil(t, err)
+				assert.Equal(t, tc.Version(manifest.EstafetteVersionParams{AutoIncrement: 31, Branch: branch, Revision: "5a2d5b2c"}), version, branch)
+			}
+		}
+	})
+}
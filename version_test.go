@@ -0,0 +1,229 @@
+package contracts
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Run("ReturnsVersionForValidVersions", func(t *testing.T) {
+
+		testCases := []struct {
+			version  string
+			expected Version
+		}{
+			{"0.0.0", Version{}},
+			{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+			{"v1.2.3", Version{Major: 1, Minor: 2, Patch: 3}},
+			{"0.0.125", Version{Patch: 125}},
+			{"1.0.23-feature-branch", Version{Major: 1, Patch: 23, Label: "feature-branch"}},
+			{"2.1.7-dependabot-npm-and-yarn-lodash", Version{Major: 2, Minor: 1, Patch: 7, Label: "dependabot-npm-and-yarn-lodash"}},
+			{"1.2.3-beta.11", Version{Major: 1, Minor: 2, Patch: 3, Label: "beta.11"}},
+			{"1.2.3+5a2d5b2", Version{Major: 1, Minor: 2, Patch: 3, Metadata: "5a2d5b2"}},
+			{"1.2.3-rc.1+5a2d5b2", Version{Major: 1, Minor: 2, Patch: 3, Label: "rc.1", Metadata: "5a2d5b2"}},
+		}
+
+		for _, tc := range testCases {
+			// act
+			version, err := ParseVersion(tc.version)
+
+			assert.Nil(t, err, tc.version)
+			assert.Equal(t, tc.expected, version, tc.version)
+		}
+	})
+
+	t.Run("ReturnsErrorForInvalidVersions", func(t *testing.T) {
+
+		testCases := []string{
+			"",
+			"1",
+			"1.2",
+			"1.2.x",
+			"01.2.3",
+			"1.2.3-",
+			"1.2.3-beta..1",
+			"1.2.3-feature_branch",
+			"5a2d5b2c1e9f",
+			"1.2.3.4",
+		}
+
+		for _, tc := range testCases {
+			// act
+			_, err := ParseVersion(tc)
+
+			assert.NotNil(t, err, tc)
+		}
+	})
+}
+
+func TestVersionString(t *testing.T) {
+	t.Run("ReturnsParsedVersion", func(t *testing.T) {
+
+		testCases := []string{
+			"0.0.0",
+			"1.2.3",
+			"1.0.23-feature-branch",
+			"1.2.3-rc.1+5a2d5b2",
+		}
+
+		for _, tc := range testCases {
+			version, err := ParseVersion(tc)
+			if !assert.Nil(t, err, tc) {
+				return
+			}
+
+			// act
+			versionString := version.String()
+
+			assert.Equal(t, tc, versionString)
+		}
+	})
+}
+
+func TestVersionCompare(t *testing.T) {
+	t.Run("ReturnsPrecedence", func(t *testing.T) {
+
+		testCases := []struct {
+			a        string
+			b        string
+			expected int
+		}{
+			{"1.2.3", "1.2.3", 0},
+			{"1.2.3", "1.2.4", -1},
+			{"1.2.10", "1.2.9", 1},
+			{"1.10.0", "1.9.99", 1},
+			{"2.0.0", "10.0.0", -1},
+			{"1.2.3-feature", "1.2.3", -1},
+			{"1.2.3", "1.2.3-feature", 1},
+			{"1.2.4-feature", "1.2.3", 1},
+			{"1.2.3-alpha", "1.2.3-beta", -1},
+			{"1.2.3-beta.2", "1.2.3-beta.11", -1},
+			{"1.2.3-beta.11", "1.2.3-beta.rc", -1},
+			{"1.2.3-beta", "1.2.3-beta.1", -1},
+			{"1.2.3+5a2d5b2", "1.2.3+9f0e1c3", 0},
+		}
+
+		for _, tc := range testCases {
+			a, errA := ParseVersion(tc.a)
+			b, errB := ParseVersion(tc.b)
+			if !assert.Nil(t, errA) || !assert.Nil(t, errB) {
+				return
+			}
+
+			// act
+			comparison := a.Compare(b)
+
+			assert.Equal(t, tc.expected, comparison, "%v vs %v", tc.a, tc.b)
+			assert.Equal(t, -tc.expected, b.Compare(a), "%v vs %v", tc.b, tc.a)
+			assert.Equal(t, tc.expected < 0, a.LessThan(b), "%v vs %v", tc.a, tc.b)
+		}
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	t.Run("ReturnsPrecedenceIncludingUnparsableVersions", func(t *testing.T) {
+
+		testCases := []struct {
+			a        string
+			b        string
+			expected int
+		}{
+			{"1.0.9", "1.0.10", -1},
+			{"5a2d5b2", "1.0.0", -1},
+			{"1.0.0", "5a2d5b2", 1},
+			{"5a2d5b2", "9f0e1c3", -1},
+			{"", "", 0},
+		}
+
+		for _, tc := range testCases {
+			// act
+			comparison := CompareVersions(tc.a, tc.b)
+
+			assert.Equal(t, tc.expected, comparison, "%v vs %v", tc.a, tc.b)
+		}
+	})
+}
+
+func TestSortVersions(t *testing.T) {
+	t.Run("SortsFromLowestToHighest", func(t *testing.T) {
+
+		versions := []string{"1.0.10", "1.0.9-feature", "custom", "1.0.9", "0.9.100", "1.0.2"}
+
+		// act
+		SortVersions(versions)
+
+		assert.Equal(t, []string{"custom", "0.9.100", "1.0.2", "1.0.9-feature", "1.0.9", "1.0.10"}, versions)
+	})
+}
+
+func TestSortBuildsByVersion(t *testing.T) {
+	t.Run("SortsFromHighestToLowest", func(t *testing.T) {
+
+		builds := []*Build{{ID: "1", BuildVersion: "1.0.9"}, {ID: "2", BuildVersion: "1.0.10"}, {ID: "3", BuildVersion: "1.0.11-feature"}}
+
+		// act
+		SortBuildsByVersion(builds)
+
+		assert.Equal(t, "3", builds[0].ID)
+		assert.Equal(t, "2", builds[1].ID)
+		assert.Equal(t, "1", builds[2].ID)
+	})
+}
+
+func TestGetLatestReleasePerTarget(t *testing.T) {
+	t.Run("ReturnsHighestVersionPerTargetAndAction", func(t *testing.T) {
+
+		releases := []*Release{
+			{ID: "1", Name: "production", ReleaseVersion: "1.0.10"},
+			{ID: "2", Name: "production", ReleaseVersion: "1.0.9"},
+			{ID: "3", Name: "staging", ReleaseVersion: "1.0.11"},
+			{ID: "4", Name: "production", Action: "deploy-canary", ReleaseVersion: "1.0.11"},
+		}
+
+		// act
+		latestReleases := GetLatestReleasePerTarget(releases)
+
+		assert.Equal(t, 3, len(latestReleases))
+		assert.Equal(t, "1", latestReleases["production"].ID)
+		assert.Equal(t, "3", latestReleases["staging"].ID)
+		assert.Equal(t, "4", latestReleases["production/deploy-canary"].ID)
+	})
+}
+
+func TestNewVersionFromConfig(t *testing.T) {
+	t.Run("ReturnsVersionFromConfig", func(t *testing.T) {
+
+		major := 1
+		minor := 4
+		patch := "12"
+		autoIncrement := 15
+		label := "feature-branch"
+
+		testCases := []struct {
+			config   VersionConfig
+			expected string
+		}{
+			{VersionConfig{Version: "1.4.12", Major: &major, Minor: &minor, Patch: &patch}, "1.4.12"},
+			{VersionConfig{Version: "1.4.12-feature-branch", Major: &major, Minor: &minor, Patch: &patch, Label: &label}, "1.4.12-feature-branch"},
+			{VersionConfig{Major: &major, Minor: &minor, AutoIncrement: &autoIncrement}, "1.4.15"},
+			{VersionConfig{Version: "2.0.3-beta"}, "2.0.3-beta"},
+		}
+
+		for _, tc := range testCases {
+			// act
+			version, err := NewVersionFromConfig(tc.config)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, version.String())
+		}
+	})
+
+	t.Run("ReturnsErrorForCustomVersion", func(t *testing.T) {
+
+		// act
+		_, err := NewVersionFromConfig(VersionConfig{Version: "5a2d5b2"})
+
+		assert.NotNil(t, err)
+	})
+}
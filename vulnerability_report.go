@@ -0,0 +1,267 @@
+package contracts
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	vulnerabilityReportSourceTrivy = "trivy"
+	vulnerabilityReportSourceGrype = "grype"
+)
+
+type trivyReport struct {
+	SchemaVersion int           `json:"SchemaVersion"`
+	ArtifactName  string        `json:"ArtifactName"`
+	ArtifactType  string        `json:"ArtifactType"`
+	Results       []trivyResult `json:"Results"`
+}
+
+type trivyResult struct {
+	Target          string               `json:"Target"`
+	Vulnerabilities []trivyVulnerability `json:"Vulnerabilities"`
+}
+
+type trivyVulnerability struct {
+	VulnerabilityID  string               `json:"VulnerabilityID"`
+	PkgName          string               `json:"PkgName"`
+	InstalledVersion string               `json:"InstalledVersion"`
+	FixedVersion     string               `json:"FixedVersion"`
+	Severity         string               `json:"Severity"`
+	Title            string               `json:"Title"`
+	PrimaryURL       string               `json:"PrimaryURL"`
+	References       []string             `json:"References"`
+	CVSS             map[string]trivyCVSS `json:"CVSS"`
+}
+
+type trivyCVSS struct {
+	V2Score float64 `json:"V2Score"`
+	V3Score float64 `json:"V3Score"`
+}
+
+type grypeReport struct {
+	Matches []grypeMatch `json:"matches"`
+	Source  struct {
+		Type   string          `json:"type"`
+		Target json.RawMessage `json:"target"`
+	} `json:"source"`
+}
+
+type grypeMatch struct {
+	Vulnerability struct {
+		ID          string   `json:"id"`
+		DataSource  string   `json:"dataSource"`
+		Severity    string   `json:"severity"`
+		Description string   `json:"description"`
+		URLs        []string `json:"urls"`
+		CVSS        []struct {
+			Metrics struct {
+				BaseScore float64 `json:"baseScore"`
+			} `json:"metrics"`
+		} `json:"cvss"`
+		Fix struct {
+			Versions []string `json:"versions"`
+			State    string   `json:"state"`
+		} `json:"fix"`
+	} `json:"vulnerability"`
+	Artifact struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"artifact"`
+}
+
+type grypeImageTarget struct {
+	UserInput string `json:"userInput"`
+}
+
+// ParseTrivyReport turns a trivy json report into a notification record based on the template
+func ParseTrivyReport(data []byte, template NotificationRecord) (*NotificationRecord, error) {
+
+	// trivy before schema version 2 outputs a list of results instead of a report object
+	var report trivyReport
+	var results []trivyResult
+	if err := json.Unmarshal(data, &report); err == nil {
+		results = report.Results
+	} else if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("Trivy report can't be unmarshalled: %w", err)
+	}
+
+	record := template
+	if record.Source == "" {
+		record.Source = vulnerabilityReportSourceTrivy
+	}
+	if report.ArtifactType == "" || report.ArtifactType == "container_image" {
+		record.setContainerLink(report.ArtifactName)
+	}
+
+	notifications := []Notification{}
+	for _, r := range results {
+		for _, v := range r.Vulnerabilities {
+			detail := &VulnerabilityDetail{
+				ID:               v.VulnerabilityID,
+				Package:          v.PkgName,
+				InstalledVersion: v.InstalledVersion,
+				FixedVersion:     v.FixedVersion,
+				Links:            []string{},
+			}
+			// prefer v3 scores and only fall back to v2 if no vendor has a v3 score
+			var v2Score float64
+			for _, c := range v.CVSS {
+				if c.V3Score > detail.CVSSScore {
+					detail.CVSSScore = c.V3Score
+				}
+				if c.V2Score > v2Score {
+					v2Score = c.V2Score
+				}
+			}
+			if detail.CVSSScore == 0 {
+				detail.CVSSScore = v2Score
+			}
+			if v.PrimaryURL != "" {
+				detail.Links = append(detail.Links, v.PrimaryURL)
+			}
+			detail.Links = append(detail.Links, v.References...)
+
+			notifications = append(notifications, newVulnerabilityNotification(ParseNotificationLevel(v.Severity), detail, v.Title))
+		}
+	}
+
+	record.Notifications = DeduplicateNotifications(append(record.Notifications, notifications...))
+	SortNotifications(record.Notifications)
+
+	return &record, nil
+}
+
+// ParseGrypeReport turns a grype json report into a notification record based on the template
+func ParseGrypeReport(data []byte, template NotificationRecord) (*NotificationRecord, error) {
+
+	var report grypeReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("Grype report can't be unmarshalled: %w", err)
+	}
+
+	record := template
+	if record.Source == "" {
+		record.Source = vulnerabilityReportSourceGrype
+	}
+	if report.Source.Type == "image" {
+		var target grypeImageTarget
+		if err := json.Unmarshal(report.Source.Target, &target); err == nil {
+			record.setContainerLink(target.UserInput)
+		}
+	}
+
+	notifications := []Notification{}
+	for _, m := range report.Matches {
+		detail := &VulnerabilityDetail{
+			ID:               m.Vulnerability.ID,
+			Package:          m.Artifact.Name,
+			InstalledVersion: m.Artifact.Version,
+			Links:            []string{},
+		}
+		if m.Vulnerability.Fix.State == "fixed" && len(m.Vulnerability.Fix.Versions) > 0 {
+			detail.FixedVersion = strings.Join(m.Vulnerability.Fix.Versions, ", ")
+		}
+		for _, c := range m.Vulnerability.CVSS {
+			if c.Metrics.BaseScore > detail.CVSSScore {
+				detail.CVSSScore = c.Metrics.BaseScore
+			}
+		}
+		if m.Vulnerability.DataSource != "" {
+			detail.Links = append(detail.Links, m.Vulnerability.DataSource)
+		}
+		for _, u := range m.Vulnerability.URLs {
+			if u != m.Vulnerability.DataSource {
+				detail.Links = append(detail.Links, u)
+			}
+		}
+
+		notifications = append(notifications, newVulnerabilityNotification(ParseNotificationLevel(m.Vulnerability.Severity), detail, m.Vulnerability.Description))
+	}
+
+	record.Notifications = DeduplicateNotifications(append(record.Notifications, notifications...))
+	SortNotifications(record.Notifications)
+
+	return &record, nil
+}
+
+// ParseNotificationLevel maps scanner severities like CRITICAL or Negligible to a notification level
+func ParseNotificationLevel(severity string) NotificationLevel {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical":
+		return NotificationLevelCritical
+	case "high":
+		return NotificationLevelHigh
+	case "medium", "moderate":
+		return NotificationLevelMedium
+	case "low", "negligible":
+		return NotificationLevelLow
+	}
+
+	return NotificationLevelUnknown
+}
+
+// SortNotifications sorts notifications by descending level and cvss score, then by vulnerability id and package
+func SortNotifications(notifications []Notification) {
+	sort.SliceStable(notifications, func(i, j int) bool {
+		a, b := notifications[i], notifications[j]
+		if a.Level.Severity() != b.Level.Severity() {
+			return a.Level.Severity() > b.Level.Severity()
+		}
+		if a.Vulnerability == nil || b.Vulnerability == nil {
+			return a.Vulnerability != nil && b.Vulnerability == nil
+		}
+		if a.Vulnerability.CVSSScore != b.Vulnerability.CVSSScore {
+			return a.Vulnerability.CVSSScore > b.Vulnerability.CVSSScore
+		}
+		if a.Vulnerability.ID != b.Vulnerability.ID {
+			return a.Vulnerability.ID < b.Vulnerability.ID
+		}
+		return a.Vulnerability.Package < b.Vulnerability.Package
+	})
+}
+
+func newVulnerabilityNotification(level NotificationLevel, detail *VulnerabilityDetail, title string) Notification {
+	message := fmt.Sprintf("%v in %v %v", detail.ID, detail.Package, detail.InstalledVersion)
+	if detail.FixedVersion != "" {
+		message += fmt.Sprintf(" (fixed in %v)", detail.FixedVersion)
+	}
+	if title != "" {
+		message += ": " + title
+	}
+
+	return Notification{
+		Type:          NotificationTypeVulnerability,
+		Level:         level,
+		Message:       message,
+		Vulnerability: detail,
+	}
+}
+
+// setContainerLink sets the link to the scanned image if the record doesn't link to anything yet
+func (nr *NotificationRecord) setContainerLink(image string) {
+	if image == "" || nr.LinkID != "" || (nr.LinkType != NotificationLinkTypeUnknown && nr.LinkType != NotificationLinkTypeContainer) {
+		return
+	}
+
+	// strip digest and split the tag from the repository
+	image = strings.Split(image, "@")[0]
+	repository, tag := image, ""
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		repository, tag = image[:i], image[i+1:]
+	}
+
+	nr.LinkType = NotificationLinkTypeContainer
+	nr.LinkID = repository
+	// copy the detail, so the template passed by the caller isn't changed
+	containerDetail := ContainerLinkDetail{}
+	if nr.ContainerDetail != nil {
+		containerDetail = *nr.ContainerDetail
+	}
+	if containerDetail.Tag == "" {
+		containerDetail.Tag = tag
+	}
+	nr.ContainerDetail = &containerDetail
+}
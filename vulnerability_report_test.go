@@ -0,0 +1,156 @@
+package contracts
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrivyReport(t *testing.T) {
+	t.Run("ReturnsContainerRecordWithVulnerabilitiesSortedBySeverity", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("trivy-report-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		record, err := ParseTrivyReport(bytes, NotificationRecord{})
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, NotificationLinkTypeContainer, record.LinkType)
+		assert.Equal(t, "extensions/docker", record.LinkID)
+		assert.Equal(t, "1.2.3", record.ContainerDetail.Tag)
+		assert.Equal(t, "trivy", record.Source)
+		if !assert.Equal(t, 3, len(record.Notifications)) {
+			return
+		}
+		assert.Equal(t, NotificationTypeVulnerability, record.Notifications[0].Type)
+		assert.Equal(t, NotificationLevelCritical, record.Notifications[0].Level)
+		assert.Equal(t, "CVE-2021-36159 in apk-tools 2.10.6-r0 (fixed in 2.10.7-r0): libfetch: out-of-bounds read in fetch_ftp_line", record.Notifications[0].Message)
+		assert.Equal(t, &VulnerabilityDetail{
+			ID:               "CVE-2021-36159",
+			Package:          "apk-tools",
+			InstalledVersion: "2.10.6-r0",
+			FixedVersion:     "2.10.7-r0",
+			CVSSScore:        9.1,
+			Links:            []string{"https://avd.aquasec.com/nvd/cve-2021-36159", "https://github.com/freebsd/freebsd-src/commits/main/lib/libfetch"},
+		}, record.Notifications[0].Vulnerability)
+		assert.Equal(t, NotificationLevelHigh, record.Notifications[1].Level)
+		assert.Equal(t, NotificationLevelLow, record.Notifications[2].Level)
+		assert.Equal(t, "GHSA-8c26-wmh5-6g9v", record.Notifications[2].Vulnerability.ID)
+	})
+
+	t.Run("KeepsPipelineLinkFromTemplate", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("trivy-report-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+		template := NotificationRecord{
+			LinkType: NotificationLinkTypePipeline,
+			LinkID:   "github.com/estafette/estafette-ci-api",
+			PipelineDetail: &PipelineLinkDetail{
+				Branch:   "main",
+				Revision: "f0677f01cc6d54a5b042224a9eb374e98f979985",
+				Version:  "1.0.5",
+			},
+		}
+
+		// act
+		record, err := ParseTrivyReport(bytes, template)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, NotificationLinkTypePipeline, record.LinkType)
+		assert.Equal(t, "github.com/estafette/estafette-ci-api", record.LinkID)
+		assert.Equal(t, "1.0.5", record.PipelineDetail.Version)
+		assert.Nil(t, record.ContainerDetail)
+		assert.Equal(t, 3, len(record.Notifications))
+	})
+
+	t.Run("PrefersV3ScoreOverHigherV2Score", func(t *testing.T) {
+
+		bytes := []byte(`{"SchemaVersion":2,"ArtifactName":"extensions/docker:1.2.3","ArtifactType":"container_image","Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-2020-28928","PkgName":"musl","Severity":"MEDIUM","CVSS":{"nvd":{"V2Score":7.5,"V3Score":5.3},"redhat":{"V2Score":7.8}}}]}]}`)
+
+		// act
+		record, err := ParseTrivyReport(bytes, NotificationRecord{})
+
+		if !assert.Nil(t, err) || !assert.Equal(t, 1, len(record.Notifications)) {
+			return
+		}
+		assert.Equal(t, 5.3, record.Notifications[0].Vulnerability.CVSSScore)
+	})
+
+	t.Run("DoesNotChangeContainerDetailOfTemplate", func(t *testing.T) {
+
+		bytes := []byte(`{"SchemaVersion":2,"ArtifactName":"extensions/docker:1.2.3","ArtifactType":"container_image","Results":[]}`)
+		template := NotificationRecord{ContainerDetail: &ContainerLinkDetail{}}
+
+		// act
+		record, err := ParseTrivyReport(bytes, template)
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, "1.2.3", record.ContainerDetail.Tag)
+		assert.Equal(t, "", template.ContainerDetail.Tag)
+	})
+
+	t.Run("ReturnsErrorForInvalidJSON", func(t *testing.T) {
+
+		// act
+		_, err := ParseTrivyReport([]byte("not json"), NotificationRecord{})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestParseGrypeReport(t *testing.T) {
+	t.Run("ReturnsContainerRecordWithVulnerabilities", func(t *testing.T) {
+
+		bytes, err := ioutil.ReadFile("grype-report-test.json")
+		if !assert.Nil(t, err) {
+			return
+		}
+
+		// act
+		record, err := ParseGrypeReport(bytes, NotificationRecord{})
+
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, NotificationLinkTypeContainer, record.LinkType)
+		assert.Equal(t, "estafette/estafette-ci-api", record.LinkID)
+		assert.Equal(t, "1.0.5", record.ContainerDetail.Tag)
+		assert.Equal(t, "grype", record.Source)
+		if !assert.Equal(t, 2, len(record.Notifications)) {
+			return
+		}
+		assert.Equal(t, NotificationLevelCritical, record.Notifications[0].Level)
+		assert.Equal(t, &VulnerabilityDetail{
+			ID:               "CVE-2021-3711",
+			Package:          "libssl1.1",
+			InstalledVersion: "1.1.1k-r0",
+			FixedVersion:     "1.1.1l-r0",
+			CVSSScore:        9.8,
+			Links:            []string{"https://nvd.nist.gov/vuln/detail/CVE-2021-3711", "https://www.openssl.org/news/secadv/20210824.txt"},
+		}, record.Notifications[0].Vulnerability)
+		assert.Equal(t, NotificationLevelLow, record.Notifications[1].Level)
+		assert.Equal(t, "", record.Notifications[1].Vulnerability.FixedVersion)
+	})
+}
+
+func TestParseNotificationLevel(t *testing.T) {
+	t.Run("MapsScannerSeverities", func(t *testing.T) {
+		assert.Equal(t, NotificationLevelCritical, ParseNotificationLevel("CRITICAL"))
+		assert.Equal(t, NotificationLevelHigh, ParseNotificationLevel("High"))
+		assert.Equal(t, NotificationLevelMedium, ParseNotificationLevel("moderate"))
+		assert.Equal(t, NotificationLevelLow, ParseNotificationLevel("Negligible"))
+		assert.Equal(t, NotificationLevelUnknown, ParseNotificationLevel("UNKNOWN"))
+	})
+}
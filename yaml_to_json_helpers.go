@@ -2,6 +2,7 @@ package contracts
 
 import "fmt"
 
+// cleanUpStringMap fixes map[interface{}]interface breaking json.marshal - see https://github.com/go-yaml/yaml/issues/139
 func cleanUpStringMap(in map[string]interface{}) map[string]interface{} {
 	result := make(map[string]interface{})
 	for k, v := range in {